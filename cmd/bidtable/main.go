@@ -0,0 +1,199 @@
+// Command bidtable generates the table of expected tricks used by the bid
+// advisor (see player.BidTable).
+//
+// It deals many hands, and plays each one out in every denomination, with
+// player 0 as the contractor. The hands are played either by bots of the
+// given level (without the deliberate mistakes the easier levels make), or
+// double-dummy by the solver. For each denomination, it
+// records the tricks taken by the contractor's side against the features of
+// player 0's hand (before picking up the kitty).
+//
+// Misère isn't included, as the tricks taken don't depend on these features.
+//
+// To update the table built into the program, run
+//
+//	go run ./cmd/bidtable -out player/bidtable.txt
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/controller"
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/player"
+	"github.com/barrettj12/500/solver"
+	"github.com/barrettj12/500/util"
+
+	c "github.com/barrettj12/collections"
+)
+
+func main() {
+	out := flag.String("out", "bidtable.txt", "file to write the table to")
+	deals := flag.Int("deals", 1000000, "number of deals to play")
+	bot := flag.String("bot", "medium", fmt.Sprintf(
+		`who plays the hands: "solver", or a bot level (one of %s)`, strings.Join(player.LevelNames(), ", ")))
+	seed := flag.Int64("seed", 1, "seed for the deals")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of deals played at once")
+	flag.Parse()
+
+	play := playSolver
+	if *bot != "solver" {
+		level, err := findLevel(*bot)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		play = func(seed int64, bid game.Bid) (*c.List[card.Card], int) {
+			return playBots(seed, bid, level)
+		}
+	}
+
+	seeds := make(chan int64, *workers)
+	go func() {
+		r := rand.New(rand.NewSource(*seed))
+		for i := 0; i < *deals; i++ {
+			if i > 0 && i%10000 == 0 {
+				fmt.Fprintf(os.Stderr, "%d deals played\n", i)
+			}
+			seeds <- r.Int63()
+		}
+		close(seeds)
+	}()
+
+	var (
+		mu    sync.Mutex
+		table = player.NewBidTable()
+		wg    sync.WaitGroup
+	)
+	for w := 0; w < *workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for seed := range seeds {
+				for _, bid := range denominations() {
+					hand, tricks := play(seed, bid)
+					f := player.Features(hand, bid)
+					mu.Lock()
+					table.Add(bid, f, tricks)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	file := util.E(os.Create(*out))
+	by := *bot
+	if *bot != "solver" {
+		by += " bots, without deliberate mistakes"
+	}
+	fmt.Fprintf(file, "# Generated by cmd/bidtable: %d deals played by %s\n", *deals, by)
+	util.E0(table.Write(file))
+	util.E0(file.Close())
+}
+
+// denominations returns a six-trick bid in each denomination.
+func denominations() []game.Bid {
+	return []game.Bid{
+		game.SuitBid{6, card.Spades}, game.SuitBid{6, card.Clubs},
+		game.SuitBid{6, card.Diamonds}, game.SuitBid{6, card.Hearts},
+		game.NoTrumpsBid{Tricks: 6},
+	}
+}
+
+// playSolver plays the deal double-dummy, with the contractor discarding
+// using player.PlanDiscard. It returns the contractor's hand before picking
+// up the kitty, and the tricks taken by the contractor's side.
+func playSolver(seed int64, bid game.Bid) (*c.List[card.Card], int) {
+	// Deal the same way as the controller
+	deck := game.GetDeck()
+	util.Shuffle(rand.New(rand.NewSource(seed)), deck)
+	var hands [4]*c.List[card.Card]
+	for i := range hands {
+		hands[i] = util.E(deck.CopyPart(i*10, i*10+10))
+	}
+	dealt := hands[0].Copy()
+
+	hand := hands[0]
+	hand.Append(*util.E(deck.CopyPart(40, 43))...)
+	discards := player.PlanDiscard(hand, bid)
+	hands[0] = hand.Filter(func(_ int, cd card.Card) bool { return !discards.Contains(cd) })
+
+	tricks := solver.Solve(solver.Position{Bid: bid, Contractor: 0, Hands: hands, Leader: 0})
+	return dealt, tricks
+}
+
+// findLevel returns the named bot level, without its deliberate mistakes, so
+// that the table reflects the bots' best play.
+func findLevel(name string) (player.Level, error) {
+	for _, l := range player.Levels {
+		if l.Name == name {
+			l.ErrorRate = 0
+			return l, nil
+		}
+	}
+	return player.Level{}, fmt.Errorf("unknown bot level %q (want one of %s)",
+		name, strings.Join(player.LevelNames(), ", "))
+}
+
+// playBots plays the deal with bots of the given level, with player 0 bidding
+// the contract and everyone else passing. It returns the contractor's hand
+// before picking up the kitty, and the tricks taken by the contractor's side.
+func playBots(seed int64, bid game.Bid, level player.Level) (*c.List[card.Card], int) {
+	ct := controller.Controller{
+		Rand:   rand.New(rand.NewSource(seed)),
+		Pacing: player.Instant,
+	}
+	r := rand.New(rand.NewSource(seed))
+	var contractor *scriptedBidder
+	for i := range ct.Players {
+		p := &scriptedBidder{PlayerV2: player.Adapt(level.New(r, nil))}
+		if i == 0 {
+			p.bid = bid
+			contractor = p
+		}
+		ct.Players[i] = p
+	}
+	util.E0(ct.Play(context.Background()))
+
+	switch res := ct.Record().Result.(type) {
+	case game.BidWon:
+		return contractor.dealt, res.Tricks
+	case game.BidLost:
+		return contractor.dealt, res.Tricks
+	}
+	panic(fmt.Sprintf("unexpected result %v", ct.Record().Result))
+}
+
+// scriptedBidder wraps a player, making a fixed bid (or passing if bid is nil)
+// instead of the player's own bids. It also remembers the hand it was dealt.
+type scriptedBidder struct {
+	player.PlayerV2
+	bid   game.Bid
+	dealt *c.List[card.Card]
+}
+
+func (p *scriptedBidder) Notify(ctx context.Context, e player.Event) error {
+	if e, ok := e.(player.HandEvent); ok && p.dealt == nil {
+		p.dealt = e.Hand.Copy()
+	}
+	return p.PlayerV2.Notify(ctx, e)
+}
+
+func (p *scriptedBidder) Decide(ctx context.Context, r player.Request) (player.Response, error) {
+	if _, ok := r.(player.BidRequest); ok {
+		if p.bid == nil {
+			return player.BidResponse{Bid: game.Pass{}}, nil
+		}
+		return player.BidResponse{Bid: p.bid}, nil
+	}
+	return p.PlayerV2.Decide(ctx, r)
+}
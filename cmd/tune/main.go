@@ -0,0 +1,183 @@
+// Command tune improves the weights of the heuristic bot by self-play.
+//
+// It hill-climbs from a starting set of weights: each round, it changes a
+// few weights at random, and plays the new weights against the best so far
+// over a set of seeded deals. Each deal is played twice, with the teams
+// swapping seats, so that the luck of the cards cancels out. The new weights
+// are kept if they score better on average by more than two standard errors.
+// The best weights are written to the output file whenever they improve, so
+// the tuner can be stopped at any time.
+//
+// Both the bidding weights and the card-play weights (see player.Weights)
+// are tuned. The weights file can be loaded with the -weights flag of the game.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"reflect"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/barrettj12/500/controller"
+	"github.com/barrettj12/500/player"
+	"github.com/barrettj12/500/util"
+)
+
+func main() {
+	out := flag.String("out", "weights.json", "file to write the best weights to")
+	from := flag.String("from", "", "file of weights to start from (default: the built-in weights)")
+	deals := flag.Int("deals", 2000, "number of deals played in each round (each deal is played twice)")
+	rounds := flag.Int("rounds", 0, "number of rounds to run (0 for no limit)")
+	duration := flag.Duration("duration", 8*time.Hour, "time to run for (0 for no limit)")
+	step := flag.Float64("step", 0.2, "typical size of a change to a weight, in tricks")
+	seed := flag.Int64("seed", 1, "seed for the changes to the weights and the deals")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of deals played at once")
+	flag.Parse()
+	if *deals < 2 {
+		// The standard error needs at least two deals
+		fmt.Fprintln(os.Stderr, "-deals must be at least 2")
+		os.Exit(2)
+	}
+
+	best := player.DefaultWeights
+	if *from != "" {
+		w, err := player.LoadWeights(*from)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "loading weights: %v\n", err)
+			os.Exit(2)
+		}
+		best = *w
+	}
+
+	t := &tuner{
+		deals:   *deals,
+		step:    *step,
+		workers: *workers,
+		rand:    rand.New(rand.NewSource(*seed)),
+	}
+	var deadline time.Time
+	if *duration > 0 {
+		deadline = time.Now().Add(*duration)
+	}
+
+	for round := 1; *rounds == 0 || round <= *rounds; round++ {
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			break
+		}
+		candidate := t.mutate(best)
+		mean, stderr := t.match(candidate, best)
+		fmt.Printf("round %d: %+.2f ± %.2f points per deal", round, mean, stderr)
+
+		// Only keep changes which are clearly better, so noise doesn't
+		// make the weights wander
+		if mean > 2*stderr {
+			best = candidate
+			util.E0(player.SaveWeights(*out, best))
+			fmt.Printf(" - improved, written to %s", *out)
+		}
+		fmt.Println()
+	}
+	util.E0(player.SaveWeights(*out, best))
+}
+
+// tuner holds the settings of a tuning run.
+type tuner struct {
+	deals   int
+	step    float64
+	workers int
+	rand    *rand.Rand
+}
+
+// mutate returns a copy of the weights with some of them changed at random.
+func (t *tuner) mutate(w player.Weights) player.Weights {
+	v := reflect.ValueOf(&w).Elem()
+	changed := false
+	for !changed {
+		for i := 0; i < v.NumField(); i++ {
+			// Change about a quarter of the weights
+			if t.rand.Intn(4) != 0 {
+				continue
+			}
+			changed = true
+			f := v.Field(i)
+			switch f.Kind() {
+			case reflect.Float64:
+				x := f.Float() + t.rand.NormFloat64()*t.step
+				f.SetFloat(math.Max(0, math.Round(x*100)/100))
+			case reflect.Int:
+				x := f.Int() + int64(t.rand.Intn(3)-1)
+				if x < 0 {
+					x = 0
+				}
+				f.SetInt(x)
+			}
+		}
+	}
+	return w
+}
+
+// match plays the candidate weights against the best weights over the
+// round's deals. It returns the mean number of points per deal the candidate
+// team scored more than the other team, and the standard error of the mean.
+func (t *tuner) match(candidate, best player.Weights) (mean, stderr float64) {
+	seeds := make(chan int64, t.deals)
+	for i := 0; i < t.deals; i++ {
+		seeds <- t.rand.Int63()
+	}
+	close(seeds)
+
+	var (
+		mu    sync.Mutex
+		diffs []float64
+		wg    sync.WaitGroup
+	)
+	for w := 0; w < t.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for seed := range seeds {
+				// Play the deal with the candidate in each pair of seats
+				diff := playDeal(seed, &candidate, &best) - playDeal(seed, &best, &candidate)
+				mu.Lock()
+				diffs = append(diffs, float64(diff))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, d := range diffs {
+		mean += d
+	}
+	mean /= float64(len(diffs))
+	variance := 0.0
+	for _, d := range diffs {
+		variance += (d - mean) * (d - mean)
+	}
+	variance /= float64(len(diffs) - 1)
+	return mean, math.Sqrt(variance / float64(len(diffs)))
+}
+
+// playDeal plays a single deal between heuristic bots, with weights a for
+// team 0 and b for team 1. It returns team 0's score minus team 1's score.
+func playDeal(seed int64, a, b *player.Weights) int {
+	ct := controller.Controller{
+		Rand:   rand.New(rand.NewSource(seed)),
+		Pacing: player.Instant,
+	}
+	for i := range ct.Players {
+		w := a
+		if i%2 == 1 {
+			w = b
+		}
+		ct.Players[i] = player.Adapt(&player.HeuristicPlayer{Weights: w})
+	}
+	util.E0(ct.Play(context.Background()))
+	return ct.Score[0] - ct.Score[1]
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/player"
+	"github.com/barrettj12/500/util"
+)
+
+// TimeControl gives each player a time budget for the match, like a chess
+// clock. A player's clock runs while the controller waits for them to make a
+// decision.
+type TimeControl struct {
+	// Budget is each player's total time for the match.
+	Budget time.Duration
+	// Increment is added to a player's clock after each decision.
+	Increment time.Duration
+	// Penalty says what happens when a player runs out of time.
+	Penalty TimeoutPenalty
+}
+
+// TimeoutPenalty says what happens when a player runs out of time.
+type TimeoutPenalty int
+
+const (
+	// AutoPlay makes a decision on behalf of the player (e.g. passing, or
+	// playing their first valid card). All their later decisions are also
+	// made automatically.
+	AutoPlay TimeoutPenalty = iota
+	// ForfeitHand ends the hand, and the player's team forfeits it.
+	ForfeitHand
+)
+
+// Clock keeps track of the time remaining for each player. To use the same
+// time budget over a match of several hands, use the same Clock for each hand.
+type Clock struct {
+	TimeControl
+	Remaining [4]time.Duration
+}
+
+// NewClock returns a Clock giving each player the full time budget.
+func NewClock(tc TimeControl) *Clock {
+	cl := &Clock{TimeControl: tc}
+	for i := range cl.Remaining {
+		cl.Remaining[i] = tc.Budget
+	}
+	return cl
+}
+
+// forfeit is used to unwind playHand when a player forfeits the hand.
+type forfeit struct {
+	player int
+}
+
+// timedDecide sends a request to player i, running their clock (if any)
+// while they decide. If they run out of time, the clock's penalty applies.
+func (ct *Controller) timedDecide(ctx context.Context, i int, req player.Request) (player.Response, error) {
+	if ct.Clock == nil {
+		return ct.Players[i].Decide(ctx, req)
+	}
+
+	ct.notifyAll(ctx, player.ClockEvent{Remaining: ct.Clock.Remaining, Running: i})
+	tctx, cancel := context.WithTimeout(ctx, ct.Clock.Remaining[i])
+	defer cancel()
+
+	start := time.Now()
+	resp, err := ct.Players[i].Decide(tctx, req)
+	ct.Clock.Remaining[i] -= time.Since(start)
+
+	timedOut := ct.Clock.Remaining[i] <= 0 ||
+		(errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil)
+	if timedOut {
+		ct.Clock.Remaining[i] = 0
+		ct.notifyAll(ctx, player.ClockEvent{Remaining: ct.Clock.Remaining, Running: -1})
+		if ct.Clock.Penalty == ForfeitHand {
+			panic(forfeit{i})
+		}
+		return ct.autoResponse(i, req), nil
+	}
+
+	if err == nil {
+		ct.Clock.Remaining[i] += ct.Clock.Increment
+	}
+	ct.notifyAll(ctx, player.ClockEvent{Remaining: ct.Clock.Remaining, Running: -1})
+	return resp, err
+}
+
+// autoResponse returns a response to the request for a player who has run
+// out of time.
+func (ct *Controller) autoResponse(i int, req player.Request) player.Response {
+	switch r := req.(type) {
+	case player.BidRequest:
+		return player.BidResponse{Bid: game.Pass{}}
+	case player.DiscardRequest:
+		// Hand is sorted, so the first cards are low off-suit cards
+		return player.DiscardResponse{Cards: util.E(ct.hands[i].CopyPart(0, 3))}
+	case player.PlayRequest:
+		return player.PlayResponse{Index: util.E(r.ValidPlays.Get(0))}
+	case player.JokerSuitRequest:
+		return player.JokerSuitResponse{Suit: card.Spades}
+	default:
+		return player.AllowTakebackResponse{Allow: true}
+	}
+}
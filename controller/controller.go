@@ -1,8 +1,10 @@
 package controller
 
 import (
+	"context"
+	"fmt"
+	"math/rand"
 	"os"
-	"time"
 
 	"github.com/barrettj12/500/card"
 	"github.com/barrettj12/500/game"
@@ -17,35 +19,111 @@ import (
 // It keeps track of the game state and the hands, transmits events to players,
 // and contacts players to make plays, checking these plays are valid.
 type Controller struct {
-	Players [4]player.Player
-
+	Players [4]player.PlayerV2
+	// Score is the match score for each team (see game.Score). It is updated
+	// at the end of each hand.
+	Score [2]int
+	Rules game.HouseRules
+	// Rand is used to shuffle the deck. If nil, the global source is used.
+	Rand *rand.Rand
+	// Deck, if set, is dealt instead of a shuffled deck: player i is dealt
+	// cards 10i to 10i+9, and the last three cards form the kitty.
+	Deck *c.List[card.Card]
+	// StatePath is the file the game state is written to, for debugging.
+	// If empty, the game state is not written.
+	StatePath string
+	// Clock limits the time each player can take to make decisions. If nil,
+	// there is no time limit.
+	Clock *Clock
+	// Pacing decides how long to pause during the game, so that people can
+	// follow it. It is passed on to the players. If nil, player.Instant is
+	// used.
+	Pacing player.Pacing
+
+	deck       *c.List[card.Card]
 	hands      [4]*c.List[card.Card]
 	kitty      *c.List[card.Card]
-	bidHistory []bidInfo
+	bidHistory []game.BidInfo
 	bid        game.Bid
 	contractor int
+	discards   *c.List[card.Card]
 
 	leader       int
 	trickHistory [10]trickInfo
+	tricksPlayed int
+	result       game.HandResult
+
+	// Decisions made so far in this hand, which are replayed after a takeback
+	decisions []decision
+	replayPos int
+	replaying bool
+
+	// checkStep is called after each step of the game (see Simulate)
+	checkStep func() error
 }
 
-// Play plays this game of 500.
-func (ct *Controller) Play() {
-	for i := 0; i < 4; i++ {
-		ct.Players[i].NotifyPlayerNum(i)
-	}
+// Play plays this game of 500. It returns an error if any player fails to
+// respond to an event or request.
+func (ct *Controller) Play(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			a, ok := r.(abort)
+			if !ok {
+				panic(r)
+			}
+			err = a.err
+		}
+	}()
 
 	// Shuffle cards
-	deck := game.GetDeck()
-	deck.Shuffle()
+	if ct.Deck != nil {
+		ct.deck = ct.Deck.Copy()
+	} else {
+		ct.deck = game.GetDeck()
+		util.Shuffle(ct.Rand, ct.deck)
+	}
+
+	if ct.Pacing == nil {
+		ct.Pacing = player.Instant
+	}
+	ct.notifyAll(ctx, player.PacingEvent{Pacing: ct.Pacing})
+
+	// If a player takes back a bid or card, the hand is played again from the
+	// start, replaying the decisions made before the takeback.
+	for !ct.playHand(ctx) {
+	}
+	return nil
+}
+
+// playHand plays the hand dealt from ct.deck. It returns false if a player
+// took back a bid or card, in which case the hand should be played again.
+func (ct *Controller) playHand(ctx context.Context) (done bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch r := r.(type) {
+			case takeback:
+				done = false
+			case forfeit:
+				ct.finishHand(ctx, game.Forfeit{Player: r.player, Bid: ct.contract()})
+				done = true
+			default:
+				panic(r)
+			}
+		}
+	}()
+	ct.reset()
+
+	for i := 0; i < 4; i++ {
+		ct.notify(ctx, i, player.PlayerNumEvent{Player: i})
+	}
 
 	// Deal cards and notify each player of their hand
 	for i := 0; i < 4; i++ {
-		ct.hands[i] = util.E(deck.CopyPart(i*10, i*10+10))
+		ct.hands[i] = util.E(ct.deck.CopyPart(i*10, i*10+10))
 		game.NoTrumpsBid{}.SortHand(ct.hands[i])
-		ct.Players[i].NotifyHand(ct.hands[i])
+		ct.notify(ctx, i, player.HandEvent{Hand: ct.hands[i]})
 	}
-	ct.kitty = util.E(deck.CopyPart(40, 43))
+	ct.kitty = util.E(ct.deck.CopyPart(40, 43))
 
 	// Bidding
 	hasPassed := c.AsList([]bool{false, false, false, false})
@@ -55,15 +133,13 @@ func (ct *Controller) Play() {
 	bidder := 0
 
 	for {
-		ct.writeGamestate()
+		ct.step()
 
 		numPasses := hasPassed.Count(func(i int, b bool) bool { return b })
 		if numPasses == 4 {
 			// All players passed - re-deal
-			for i := 0; i < 4; i++ {
-				ct.Players[i].NotifyHandResult(game.Redeal{})
-			}
-			return
+			ct.finishHand(ctx, game.Redeal{})
+			return true
 		}
 		if winningBid != nil && numPasses == 3 {
 			// All other players have passed - bid is won
@@ -75,26 +151,20 @@ func (ct *Controller) Play() {
 			continue
 		}
 
-		newBid := retryTillValid(func() (game.Bid, bool) {
-			b := ct.Players[bidder].Bid()
-			if (b == game.Pass{}) {
-				hasPassed.Set(bidder, true)
-				return b, true
-			}
-			if winningBid == nil || b.Value() > winningBid.Value() {
-				winningBid = b
-				winningBidder = bidder
-				return b, true
-			}
-			// Otherwise, bid not valid, so we will ask again
-			return nil, false
-		})
-		ct.bidHistory = append(ct.bidHistory, bidInfo{bidder, newBid})
+		newBid := ask(ctx, ct, bidder, player.BidRequest{View: ct.view()}, func(r player.BidResponse) bool {
+			// Bid must be a pass or beat the current winning bid
+			return r.Bid == game.Pass{} || winningBid == nil || r.Bid.Value() > winningBid.Value()
+		}).Bid
+		if (newBid == game.Pass{}) {
+			hasPassed.Set(bidder, true)
+		} else {
+			winningBid = newBid
+			winningBidder = bidder
+		}
+		ct.bidHistory = append(ct.bidHistory, game.BidInfo{Player: bidder, Bid: newBid})
 
 		// Notify other players of bid
-		for i := 0; i < 4; i++ {
-			ct.Players[i].NotifyBid(bidder, newBid)
-		}
+		ct.notifyAll(ctx, player.BidEvent{Player: bidder, Bid: newBid})
 
 		// Bidding passes to next player
 		bidder = (bidder + 1) % 4
@@ -103,34 +173,41 @@ func (ct *Controller) Play() {
 	ct.bid = winningBid
 	ct.contractor = winningBidder
 	for i := 0; i < 4; i++ {
-		ct.Players[i].NotifyBidWinner(ct.contractor, ct.bid)
+		ct.notify(ctx, i, player.BidWinnerEvent{Player: ct.contractor, Bid: ct.bid})
 		// Sort hand according to bid
 		ct.bid.SortHand(ct.hands[i])
-		ct.Players[i].NotifyHand(ct.hands[i])
+		ct.notify(ctx, i, player.HandEvent{Hand: ct.hands[i]})
 	}
 
 	// Kitty
+	ct.notify(ctx, ct.contractor, player.KittyEvent{Kitty: ct.kitty.Copy()})
+	for i := 0; i < 4; i++ {
+		if i != ct.contractor {
+			ct.notify(ctx, i, player.KittyTakenEvent{Player: ct.contractor})
+		}
+	}
 	ct.hands[ct.contractor].Append(*ct.kitty...)
 	ct.bid.SortHand(ct.hands[ct.contractor])
-	ct.Players[ct.contractor].NotifyHand(ct.hands[ct.contractor])
-	ct.writeGamestate()
+	ct.notify(ctx, ct.contractor, player.HandEvent{Hand: ct.hands[ct.contractor]})
+	ct.step()
 
-	// Ask contractor to drop 3 cards from hand
-	toDrop := retryTillValid(func() (*c.Set[int], bool) {
-		toDrop := ct.Players[ct.contractor].Drop3()
-		if toDrop.Size() != 3 {
-			return nil, false
+	// Ask contractor to discard 3 cards from hand
+	ct.discards = ask(ctx, ct, ct.contractor, player.DiscardRequest{View: ct.view()}, func(r player.DiscardResponse) bool {
+		if r.Cards.Size() != 3 {
+			return false
 		}
-		for n := range *toDrop {
-			if n < 0 || n > 12 {
-				return nil, false
+		seen := c.NewSet[card.Card](3)
+		for _, cd := range *r.Cards {
+			if !ct.hands[ct.contractor].Contains(cd) || seen.Contains(cd) {
+				return false
 			}
+			seen.Add(cd)
 		}
-		return toDrop, true
-	})
-	ct.hands[ct.contractor] = ct.hands[ct.contractor].Filter(func(i int, _ card.Card) bool { return !toDrop.Contains(i) })
-	ct.Players[ct.contractor].NotifyHand(ct.hands[ct.contractor])
-	ct.writeGamestate()
+		return true
+	}).Cards
+	ct.hands[ct.contractor] = ct.hands[ct.contractor].Filter(func(_ int, cd card.Card) bool { return !ct.discards.Contains(cd) })
+	ct.notify(ctx, ct.contractor, player.HandEvent{Hand: ct.hands[ct.contractor]})
+	ct.step()
 
 	// Play game
 	ct.leader = ct.contractor
@@ -149,50 +226,49 @@ func (ct *Controller) Play() {
 			validPlays := ct.bid.ValidPlays(ct.trickHistory[trickNum].plays, ct.hands[playerNum])
 			var cardNum int
 			if validPlays.Size() == 1 {
-				time.Sleep(player.SLEEP)
+				if !ct.replaying {
+					player.Sleep(ct.Pacing, player.PauseAutoPlay)
+				}
 				cardNum = util.E(validPlays.Get(0))
 			} else {
-				cardNum = retryTillValid(func() (int, bool) {
-					cardNum := ct.Players[playerNum].Play(
-						ct.trickHistory[trickNum].plays, // trick so far
-						validPlays,
-					)
-					return cardNum, validPlays.Contains(cardNum)
-				})
+				cardNum = ask(ctx, ct, playerNum, player.PlayRequest{
+					Trick:      ct.trickHistory[trickNum].plays, // trick so far
+					ValidPlays: validPlays,
+					View:       ct.view(),
+				}, func(r player.PlayResponse) bool {
+					return validPlays.Contains(r.Index)
+				}).Index
 			}
 
 			cd := util.E(ct.hands[playerNum].Remove(cardNum))
 			ct.trickHistory[trickNum].AddPlay(playerNum, cd)
 
 			// Handle Joker lead in no trumps / misere
-			// TODO: doesn't seem to be working
 			if cd == card.JokerCard && playerNum == ct.leader {
+				anySuit := func(player.JokerSuitResponse) bool { return true }
 				switch b := ct.bid.(type) {
-				case *game.NoTrumpsBid:
-					jokerSuit := ct.Players[playerNum].JokerSuit()
-					b.JokerSuit = jokerSuit
-				case *game.MisereBid:
-					jokerSuit := ct.Players[playerNum].JokerSuit()
-					b.NoTrumpsBid.JokerSuit = jokerSuit
+				case game.NoTrumpsBid:
+					b.JokerSuit = ask(ctx, ct, playerNum, player.JokerSuitRequest{View: ct.view()}, anySuit).Suit
+					ct.bid = b
+				case game.MisereBid:
+					b.NoTrumpsBid.JokerSuit = ask(ctx, ct, playerNum, player.JokerSuitRequest{View: ct.view()}, anySuit).Suit
+					ct.bid = b
 				}
 			}
 
 			// Notify players of played card
-			for i := 0; i < 4; i++ {
-				ct.Players[i].NotifyPlay(playerNum, cd)
-			}
-			ct.Players[playerNum].NotifyHand(ct.hands[playerNum])
-			ct.writeGamestate()
+			ct.notifyAll(ctx, player.PlayEvent{Player: playerNum, Card: cd})
+			ct.notify(ctx, playerNum, player.HandEvent{Hand: ct.hands[playerNum]})
+			ct.step()
 		}
 
 		// Determine winner
 		winner := ct.trickHistory[trickNum].Winner(ct.bid)
 		ct.leader = winner
 
-		for i := 0; i < 4; i++ {
-			ct.Players[i].NotifyTrickWinner(winner)
-		}
-		ct.writeGamestate()
+		ct.tricksPlayed++
+		ct.notifyAll(ctx, player.TrickWinnerEvent{Player: winner})
+		ct.step()
 	}
 
 	// Determine hand result
@@ -205,13 +281,133 @@ func (ct *Controller) Play() {
 
 	var res game.HandResult
 	if ct.bid.Won(teamTricks) {
-		res = game.BidWon{ct.bid, teamTricks}
+		res = game.BidWon{ct.contract(), teamTricks}
 	} else {
-		res = game.BidLost{ct.bid, teamTricks}
+		res = game.BidLost{ct.contract(), teamTricks}
+	}
+
+	ct.finishHand(ctx, res)
+	return true
+}
+
+// finishHand records the result of the hand, and updates the score.
+func (ct *Controller) finishHand(ctx context.Context, res game.HandResult) {
+	ct.result = res
+	score := game.Score(res, ct.contractor)
+	ct.Score[0] += score[0]
+	ct.Score[1] += score[1]
+	ct.step()
+	ct.notifyAll(ctx, player.HandResultEvent{Result: res})
+}
+
+// reset clears the state of the hand, ready for it to be (re)played.
+func (ct *Controller) reset() {
+	ct.hands = [4]*c.List[card.Card]{}
+	ct.kitty = nil
+	ct.bidHistory = nil
+	ct.bid = nil
+	ct.contractor = 0
+	ct.discards = nil
+	ct.leader = 0
+	ct.trickHistory = [10]trickInfo{}
+	ct.tricksPlayed = 0
+	ct.result = nil
+	ct.replayPos = 0
+}
+
+// view returns a View of the current public game state.
+func (ct *Controller) view() game.View {
+	v := game.View{
+		Bids:       append([]game.BidInfo(nil), ct.bidHistory...),
+		Bid:        ct.bid,
+		Contractor: -1,
+		Score:      ct.Score,
+		Rules:      ct.Rules,
 	}
+	if ct.bid != nil {
+		v.Contractor = ct.contractor
+	}
+
+	v.Tricks = ct.tricks()
 
 	for i := 0; i < 4; i++ {
-		ct.Players[i].NotifyHandResult(res)
+		if ct.hands[i] != nil {
+			v.CardsLeft[i] = ct.hands[i].Size()
+		}
+	}
+
+	// In open misere, the contractor's hand is exposed after the opening lead
+	if b, ok := ct.bid.(game.MisereBid); ok && b.Open {
+		if ct.tricksPlayed > 0 || (ct.trickHistory[0].plays != nil && ct.trickHistory[0].plays.Size() > 0) {
+			v.Exposed[ct.contractor] = ct.hands[ct.contractor].Copy()
+		}
+	}
+
+	return v
+}
+
+// contract returns the contract as it was won in the auction, without the
+// suit named for the Joker.
+func (ct *Controller) contract() game.Bid {
+	switch b := ct.bid.(type) {
+	case game.NoTrumpsBid:
+		b.JokerSuit = card.NoSuit
+		return b
+	case game.MisereBid:
+		b.NoTrumpsBid.JokerSuit = card.NoSuit
+		return b
+	}
+	return ct.bid
+}
+
+// Record returns a record of the hand played by the controller.
+func (ct *Controller) Record() game.HandRecord {
+	rec := game.HandRecord{
+		Bid:        ct.contract(),
+		Contractor: ct.contractor,
+		Kitty:      ct.kitty,
+		Discards:   ct.discards,
+		Tricks:     ct.tricks(),
+		Result:     ct.result,
+	}
+	if ct.deck != nil {
+		for i := range rec.Hands {
+			rec.Hands[i] = util.E(ct.deck.CopyPart(i*10, i*10+10))
+		}
+	}
+	return rec
+}
+
+// tricks returns the completed tricks in this hand.
+func (ct *Controller) tricks() []game.Trick {
+	var tricks []game.Trick
+	for i := 0; i < ct.tricksPlayed; i++ {
+		t := ct.trickHistory[i]
+		tricks = append(tricks, game.Trick{
+			Leader: t.leader,
+			Plays:  t.plays.Copy(),
+			Winner: t.winner,
+		})
+	}
+	return tricks
+}
+
+// abort is used to unwind Play when a player returns an error.
+type abort struct {
+	err error
+}
+
+// notify sends the given event to a single player.
+func (ct *Controller) notify(ctx context.Context, i int, e player.Event) {
+	if err := ct.Players[i].Notify(ctx, e); err != nil {
+		panic(abort{fmt.Errorf("notifying player %d of %T: %w", i, e, err)})
+	}
+}
+
+// notifyAll sends the given event to every player.
+func (ct *Controller) notifyAll(ctx context.Context, e player.Event) {
+	for i := 0; i < 4; i++ {
+		ct.notify(ctx, i, e)
 	}
 }
 
@@ -226,14 +422,21 @@ func retryTillValid[T any](f func() (T, bool)) T {
 	}
 }
 
-func (ct *Controller) writeGamestate() {
-	os.WriteFile(".gamestate.log", []byte(pretty.Sprint(ct)), os.ModePerm)
+// step is called after each step of the game.
+func (ct *Controller) step() {
+	ct.writeGamestate()
+	if ct.checkStep != nil {
+		if err := ct.checkStep(); err != nil {
+			panic(abort{err})
+		}
+	}
 }
 
-// bidInfo holds information about a bid.
-type bidInfo struct {
-	player int
-	bid    game.Bid
+func (ct *Controller) writeGamestate() {
+	if ct.StatePath == "" {
+		return
+	}
+	os.WriteFile(ct.StatePath, []byte(pretty.Sprint(ct)), os.ModePerm)
 }
 
 // trickInfo holds information about a trick.
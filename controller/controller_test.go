@@ -1,10 +1,15 @@
 package controller
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/barrettj12/500/card"
 	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/player"
+	"github.com/barrettj12/500/player/playertest"
+	"github.com/barrettj12/500/util"
 	c "github.com/barrettj12/collections"
 	"github.com/stretchr/testify/assert"
 )
@@ -20,3 +25,278 @@ func TestTrickInfoWinnerMisere(t *testing.T) {
 	}
 	assert.Equal(t, tr.Winner(game.MisereBid{}), 3)
 }
+
+func TestClockTimeout(t *testing.T) {
+	slow := func() [4]player.PlayerV2 {
+		var players [4]player.PlayerV2
+		for i := range players {
+			players[i] = player.Adapt(&player.RandomPlayer{})
+		}
+		return players
+	}
+	pacing := player.Schedule{player.PauseBotDecision: time.Second}
+
+	// Players who run out of time pass automatically
+	ct := Controller{
+		Players: slow(),
+		Clock:   NewClock(TimeControl{Budget: 10 * time.Millisecond, Penalty: AutoPlay}),
+		Pacing:  pacing,
+	}
+	assert.NoError(t, ct.Play(context.Background()))
+	assert.Equal(t, game.Redeal{}, ct.result)
+	assert.Equal(t, [4]time.Duration{}, ct.Clock.Remaining)
+
+	// The first bidder forfeits the hand
+	ct = Controller{
+		Players: slow(),
+		Clock:   NewClock(TimeControl{Budget: 10 * time.Millisecond, Penalty: ForfeitHand}),
+		Pacing:  pacing,
+	}
+	assert.NoError(t, ct.Play(context.Background()))
+	assert.Equal(t, game.Forfeit{Player: 0}, ct.result)
+	assert.Equal(t, [2]int{-40, 0}, ct.Score)
+}
+
+// trumpsDeal gives player 0 the eight top hearts and two side aces.
+var trumpsDeal = playertest.Deal([4][]card.Card{
+	{card.JokerCard, {card.Jack, card.Hearts}, {card.Jack, card.Diamonds}, {card.Ace, card.Hearts}, {card.King, card.Hearts},
+		{card.Queen, card.Hearts}, {10, card.Hearts}, {9, card.Hearts}, {card.Ace, card.Spades}, {card.Ace, card.Clubs}},
+	{{4, card.Hearts}, {5, card.Hearts}, {8, card.Spades}, {9, card.Spades}, {10, card.Spades},
+		{5, card.Clubs}, {6, card.Clubs}, {7, card.Clubs}, {4, card.Diamonds}, {5, card.Diamonds}},
+	{{6, card.Hearts}, {7, card.Hearts}, {card.Jack, card.Spades}, {card.Queen, card.Spades}, {card.King, card.Spades},
+		{8, card.Clubs}, {9, card.Clubs}, {10, card.Clubs}, {6, card.Diamonds}, {7, card.Diamonds}},
+	{{8, card.Hearts}, {card.Jack, card.Clubs}, {card.Queen, card.Clubs}, {card.King, card.Clubs}, {8, card.Diamonds},
+		{9, card.Diamonds}, {10, card.Diamonds}, {card.Queen, card.Diamonds}, {card.King, card.Diamonds}, {card.Ace, card.Diamonds}},
+}, []card.Card{{5, card.Spades}, {6, card.Spades}, {7, card.Spades}})
+
+func TestPlayScriptedDeal(t *testing.T) {
+	bid := game.SuitBid{7, card.Hearts}
+	kitty := []card.Card{{5, card.Spades}, {6, card.Spades}, {7, card.Spades}}
+	contractor := playertest.NewRecorder(&playertest.Scripted{
+		Bids:     []game.Bid{bid},
+		Discards: kitty,
+		// Draw trumps, then cash the aces
+		Plays: []card.Card{card.JokerCard, {card.Jack, card.Hearts}, {card.Jack, card.Diamonds}, {card.Ace, card.Hearts},
+			{card.King, card.Hearts}, {card.Queen, card.Hearts}, {10, card.Hearts}, {9, card.Hearts},
+			{card.Ace, card.Spades}, {card.Ace, card.Clubs}},
+	})
+	defender := playertest.NewRecorder(nil)
+
+	ct := Controller{Deck: trumpsDeal}
+	ct.Players[0] = player.Adapt(contractor)
+	ct.Players[1] = player.Adapt(defender)
+	for i := 2; i < 4; i++ {
+		ct.Players[i] = player.Adapt(&playertest.Scripted{})
+	}
+	assert.NoError(t, ct.Play(context.Background()))
+
+	won := game.BidWon{bid, 10}
+	assert.Equal(t, won, ct.Record().Result)
+	assert.Equal(t, c.AsList(kitty), ct.Record().Discards)
+	assert.Equal(t, [2]int{200, 0}, ct.Score)
+
+	contractor.AssertCalled(t, "NotifyKitty", c.AsList(kitty))
+	defender.AssertNotCalled(t, "NotifyKitty")
+	defender.AssertCalledInOrder(t,
+		playertest.Call{"NotifyPlayerNum", []any{1}},
+		playertest.Call{"NotifyBid", []any{0, bid}},
+		playertest.Call{"NotifyBid", []any{1, game.Pass{}}},
+		playertest.Call{"NotifyBidWinner", []any{0, bid}},
+		playertest.Call{"NotifyKittyTaken", []any{0}},
+		playertest.Call{"NotifyPlay", []any{0, card.JokerCard}},
+		playertest.Call{"NotifyPlay", []any{1, card.Card{4, card.Hearts}}},
+		playertest.Call{"NotifyTrickWinner", []any{0}},
+		playertest.Call{"NotifyHandResult", []any{won}},
+	)
+	assert.Equal(t, []any{0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, defender.Args("NotifyTrickWinner"))
+	// The defender's hand is sent when dealt, when sorted for trumps, and
+	// after each play
+	assert.Len(t, defender.Find("NotifyHand"), 12)
+}
+
+func TestPlayAllPass(t *testing.T) {
+	rec := playertest.NewRecorder(nil)
+	ct := Controller{Deck: trumpsDeal}
+	ct.Players[0] = player.Adapt(rec)
+	for i := 1; i < 4; i++ {
+		ct.Players[i] = player.Adapt(&playertest.Scripted{})
+	}
+	assert.NoError(t, ct.Play(context.Background()))
+
+	assert.Equal(t, game.Redeal{}, ct.Record().Result)
+	assert.Equal(t, []any{0, game.Pass{}, 1, game.Pass{}, 2, game.Pass{}, 3, game.Pass{}}, rec.Args("NotifyBid"))
+	rec.AssertNotCalled(t, "NotifyBidWinner")
+	rec.AssertNotCalled(t, "NotifyPlay")
+	rec.AssertCalled(t, "NotifyHandResult", game.Redeal{})
+}
+
+// requestLog wraps a player, recording the requests sent to it.
+type requestLog struct {
+	player.PlayerV2
+	reqs []player.Request
+}
+
+func (p *requestLog) Decide(ctx context.Context, r player.Request) (player.Response, error) {
+	p.reqs = append(p.reqs, r)
+	return p.PlayerV2.Decide(ctx, r)
+}
+
+func TestViewOpenMisereDiscard(t *testing.T) {
+	bid := game.MisereBid{Open: true}
+	contractor := &requestLog{PlayerV2: player.Adapt(&playertest.Scripted{Bids: []game.Bid{bid}})}
+	ct := Controller{Deck: trumpsDeal}
+	ct.Players[0] = contractor
+	for i := 1; i < 4; i++ {
+		ct.Players[i] = player.Adapt(&playertest.Scripted{})
+	}
+	assert.NoError(t, ct.Play(context.Background()))
+
+	var view *game.View
+	for _, r := range contractor.reqs {
+		if r, ok := r.(player.DiscardRequest); ok {
+			view = &r.View
+		}
+	}
+	if !assert.NotNil(t, view) {
+		return
+	}
+	// The hand isn't exposed until the opening lead
+	assert.Equal(t, bid, view.Bid)
+	assert.Equal(t, 0, view.Contractor)
+	assert.Empty(t, view.Tricks)
+	assert.Equal(t, [4]int{13, 10, 10, 10}, view.CardsLeft)
+	assert.Equal(t, [4]*c.List[card.Card]{}, view.Exposed)
+}
+
+func TestViewRules(t *testing.T) {
+	rules := game.HouseRules{NoTakebacks: true}
+	p := &requestLog{PlayerV2: player.Adapt(&playertest.Scripted{})}
+	ct := Controller{Deck: trumpsDeal, Rules: rules}
+	ct.Players[0] = p
+	for i := 1; i < 4; i++ {
+		ct.Players[i] = player.Adapt(&playertest.Scripted{})
+	}
+	assert.NoError(t, ct.Play(context.Background()))
+
+	if assert.NotEmpty(t, p.reqs) {
+		assert.Equal(t, rules, p.reqs[0].(player.BidRequest).View.Rules)
+	}
+}
+
+func TestJokerLeadNoTrumps(t *testing.T) {
+	bid := game.NoTrumpsBid{Tricks: 7}
+	contractor := &requestLog{PlayerV2: player.Adapt(&playertest.Scripted{
+		Bids:      []game.Bid{bid},
+		Discards:  []card.Card{{5, card.Spades}, {6, card.Spades}, {7, card.Spades}},
+		Plays:     []card.Card{card.JokerCard},
+		NamedSuit: card.Clubs,
+	})}
+	ct := Controller{Deck: trumpsDeal}
+	ct.Players[0] = contractor
+	for i := 1; i < 4; i++ {
+		ct.Players[i] = player.Adapt(&playertest.Scripted{})
+	}
+	assert.NoError(t, ct.Play(context.Background()))
+
+	asked := 0
+	for _, r := range contractor.reqs {
+		if _, ok := r.(player.JokerSuitRequest); ok {
+			asked++
+		}
+	}
+	assert.Equal(t, 1, asked)
+
+	// Everyone follows the named suit
+	rec := ct.Record()
+	assert.Equal(t, bid, rec.Bid)
+	first := *rec.Tricks[0].Plays
+	assert.Equal(t, card.JokerCard, first[0].Card)
+	for _, pl := range first[1:] {
+		assert.Equal(t, card.Clubs, pl.Card.Suit)
+	}
+}
+
+// takesBack wraps a player, taking back its last bid or card instead of
+// answering the given bid and play requests (counting from 1). It records
+// the takeback and replay events it is sent.
+type takesBack struct {
+	player.PlayerV2
+	at     []int
+	n      int
+	events []player.Event
+}
+
+func (p *takesBack) Notify(ctx context.Context, e player.Event) error {
+	switch e.(type) {
+	case player.TakebackEvent, player.ReplayDoneEvent:
+		p.events = append(p.events, e)
+	}
+	return p.PlayerV2.Notify(ctx, e)
+}
+
+func (p *takesBack) Decide(ctx context.Context, r player.Request) (player.Response, error) {
+	switch r.(type) {
+	case player.BidRequest, player.PlayRequest:
+		p.n++
+		for _, n := range p.at {
+			if n == p.n {
+				return player.TakebackResponse{}, nil
+			}
+		}
+	}
+	return p.PlayerV2.Decide(ctx, r)
+}
+
+func TestTakeback(t *testing.T) {
+	bid := game.SuitBid{6, card.Hearts}
+	retaken := game.SuitBid{7, card.Hearts}
+	aceSpades := card.Card{card.Ace, card.Spades}
+	kitty := []card.Card{{5, card.Spades}, {6, card.Spades}, {7, card.Spades}}
+	// Lead the ace of spades, take it back, then draw trumps
+	contractor := &takesBack{
+		PlayerV2: player.Adapt(&playertest.Scripted{
+			Bids:     []game.Bid{bid, retaken},
+			Discards: kitty,
+			Plays: []card.Card{aceSpades, card.JokerCard, {card.Jack, card.Hearts}, {card.Jack, card.Diamonds},
+				{card.Ace, card.Hearts}, {card.King, card.Hearts}, {card.Queen, card.Hearts}, {10, card.Hearts},
+				{9, card.Hearts}, aceSpades, {card.Ace, card.Clubs}},
+		}),
+		// Take back the bid at the opening lead, and the ace at the next play
+		at: []int{2, 5},
+	}
+	defender := playertest.NewRecorder(nil)
+
+	ct := Controller{Deck: trumpsDeal}
+	ct.Players[0] = contractor
+	ct.Players[1] = player.Adapt(defender)
+	for i := 2; i < 4; i++ {
+		ct.Players[i] = player.Adapt(&playertest.Scripted{})
+	}
+	assert.NoError(t, ct.Play(context.Background()))
+
+	// The hand was played with the bid and card made after the takebacks
+	rec := ct.Record()
+	assert.Equal(t, retaken, rec.Bid)
+	assert.Equal(t, game.BidWon{retaken, 10}, rec.Result)
+	assert.Equal(t, card.JokerCard, util.E(rec.Tricks[0].Plays.Get(0)).Card)
+
+	assert.Equal(t, []player.Event{
+		player.TakebackEvent{Player: 0}, player.ReplayDoneEvent{},
+		player.TakebackEvent{Player: 0}, player.ReplayDoneEvent{},
+	}, contractor.events)
+
+	// The defender sees the hand replayed from the start after each takeback
+	defender.AssertCalledInOrder(t,
+		playertest.Call{"NotifyBid", []any{0, bid}},
+		playertest.Call{"NotifyBidWinner", []any{0, bid}},
+		playertest.Call{"NotifyPlayerNum", []any{1}},
+		playertest.Call{"NotifyBid", []any{0, retaken}},
+		playertest.Call{"NotifyBidWinner", []any{0, retaken}},
+		playertest.Call{"NotifyPlay", []any{0, aceSpades}},
+		playertest.Call{"NotifyPlayerNum", []any{1}},
+		playertest.Call{"NotifyBid", []any{0, retaken}},
+		playertest.Call{"NotifyPlay", []any{0, card.JokerCard}},
+	)
+	assert.Len(t, defender.Find("NotifyPlayerNum"), 3)
+	assert.Len(t, defender.Find("NotifyHandResult"), 1)
+}
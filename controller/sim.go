@@ -0,0 +1,464 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/player"
+	"github.com/barrettj12/500/util"
+
+	c "github.com/barrettj12/collections"
+)
+
+// SimConfig configures a simulation run (see Simulate).
+type SimConfig struct {
+	// Games is the number of games to play.
+	Games int
+	// Seed is the seed for the first game. Game n uses seed Seed+n.
+	Seed int64
+	// Bots returns the players for a game. To make failing games
+	// reproducible, the bots must take all their random choices from the
+	// given Tape. If nil, four SimBots are used.
+	Bots func(t *Tape) [4]player.PlayerV2
+	// Invariants are checked after every step of every game. If nil,
+	// DefaultInvariants are used.
+	Invariants []Invariant
+	// Rules are the house rules for each game.
+	Rules game.HouseRules
+}
+
+// Invariant is a property of the game state which must hold after every
+// step of the game.
+type Invariant struct {
+	Name  string
+	Check func(ct *Controller) error
+}
+
+// InvariantError says that an invariant was broken.
+type InvariantError struct {
+	Invariant string
+	Step      int
+	Err       error
+}
+
+func (e *InvariantError) Error() string {
+	return fmt.Sprintf("invariant %q broken at step %d: %v", e.Invariant, e.Step, e.Err)
+}
+
+func (e *InvariantError) Unwrap() error {
+	return e.Err
+}
+
+// Failure describes a game in which an invariant was broken (or the
+// controller failed). It can be reproduced by calling Replay with the same
+// seed and choices.
+type Failure struct {
+	Seed    int64
+	Choices []int
+	Err     error
+}
+
+func (f *Failure) String() string {
+	return fmt.Sprintf("seed %d, choices %v: %v", f.Seed, f.Choices, f.Err)
+}
+
+// Simulate plays many games through the controller, checking the invariants
+// after every step. It returns nil if every game succeeded. Otherwise, it
+// returns the first failing game, with the bots' choices shrunk to a minimal
+// reproduction.
+func Simulate(cfg SimConfig) *Failure {
+	for n := 0; n < cfg.Games; n++ {
+		seed := cfg.Seed + int64(n)
+		tape := &Tape{r: rand.New(rand.NewSource(seed))}
+		if err := runSim(cfg, seed, tape); err != nil {
+			return shrink(cfg, &Failure{seed, tape.choices, err})
+		}
+	}
+	return nil
+}
+
+// Replay replays a single game with the given seed and bot choices, and
+// returns the error which caused it to fail (if any). Choices beyond the end
+// of the given slice are 0.
+func Replay(cfg SimConfig, seed int64, choices []int) error {
+	return runSim(cfg, seed, &Tape{choices: choices})
+}
+
+// runSim plays a single game.
+func runSim(cfg SimConfig, seed int64, tape *Tape) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	bots := cfg.Bots
+	if bots == nil {
+		bots = func(t *Tape) (players [4]player.PlayerV2) {
+			for i := range players {
+				players[i] = &SimBot{Tape: t}
+			}
+			return
+		}
+	}
+	invariants := cfg.Invariants
+	if invariants == nil {
+		invariants = DefaultInvariants
+	}
+
+	ct := &Controller{
+		Players: bots(tape),
+		Rules:   cfg.Rules,
+		Rand:    rand.New(rand.NewSource(seed)),
+		Pacing:  player.Instant,
+	}
+	steps := 0
+	ct.checkStep = func() error {
+		steps++
+		for _, inv := range invariants {
+			if err := inv.Check(ct); err != nil {
+				return &InvariantError{inv.Name, steps, err}
+			}
+		}
+		return nil
+	}
+	return ct.Play(context.Background())
+}
+
+// shrink tries to find a shorter or simpler list of choices which fails in
+// the same way as the given failure.
+func shrink(cfg SimConfig, f *Failure) *Failure {
+	sameFailure := func(choices []int) (error, bool) {
+		err := Replay(cfg, f.Seed, choices)
+		if err == nil {
+			return nil, false
+		}
+		var want, got *InvariantError
+		if errors.As(f.Err, &want) {
+			return err, errors.As(err, &got) && got.Invariant == want.Invariant
+		}
+		return err, true
+	}
+
+	best := append([]int(nil), f.Choices...)
+	bestErr := f.Err
+	try := func(choices []int) bool {
+		if err, ok := sameFailure(choices); ok {
+			best, bestErr = choices, err
+			return true
+		}
+		return false
+	}
+
+	for improved := true; improved; {
+		improved = false
+
+		// Remove chunks of choices, starting with large chunks
+		for size := len(best) / 2; size > 0; size /= 2 {
+			for i := 0; i+size <= len(best); {
+				cand := append(append([]int(nil), best[:i]...), best[i+size:]...)
+				if try(cand) {
+					improved = true
+				} else {
+					i += size
+				}
+			}
+		}
+
+		// Make individual choices smaller
+		for i := range best {
+			for _, v := range []int{0, best[i] / 2, best[i] - 1} {
+				if v < 0 || v >= best[i] {
+					continue
+				}
+				cand := append([]int(nil), best...)
+				cand[i] = v
+				if try(cand) {
+					improved = true
+					break
+				}
+			}
+		}
+	}
+
+	// Trailing zeros are implied
+	for len(best) > 0 && best[len(best)-1] == 0 {
+		best = best[:len(best)-1]
+	}
+	return &Failure{f.Seed, best, bestErr}
+}
+
+// Tape is the source of all random choices made by bots in a simulation.
+// Recording the choices allows a failing game to be replayed and shrunk.
+//
+// When shrinking, choices are made smaller, so bots should use choice 0 for
+// the simplest option (e.g. passing, or not taking back a move).
+type Tape struct {
+	r       *rand.Rand // nil when replaying
+	choices []int
+	pos     int
+}
+
+// maxChoices limits the length of a game, in case the bots never finish it.
+const maxChoices = 10000
+
+// Intn returns a choice in [0, n).
+func (t *Tape) Intn(n int) int {
+	if t.pos >= maxChoices {
+		panic("game did not finish")
+	}
+	var choice int
+	switch {
+	case t.pos < len(t.choices):
+		choice = t.choices[t.pos] % n
+	case t.r != nil:
+		choice = t.r.Intn(n)
+		t.choices = append(t.choices, choice)
+	}
+	t.pos++
+	return choice
+}
+
+// SimBot is a player for simulations, which makes random valid decisions.
+// It occasionally asks to take back its last move, to exercise the replay
+// logic in the controller.
+type SimBot struct {
+	Tape *Tape
+
+	hand *c.List[card.Card]
+}
+
+var _ player.PlayerV2 = &SimBot{}
+
+func (p *SimBot) Notify(_ context.Context, e player.Event) error {
+	if e, ok := e.(player.HandEvent); ok {
+		p.hand = e.Hand
+	}
+	return nil
+}
+
+func (p *SimBot) Decide(_ context.Context, r player.Request) (player.Response, error) {
+	switch r := r.(type) {
+	case player.BidRequest:
+		if p.Tape.Intn(50) == 49 {
+			return player.TakebackResponse{}, nil
+		}
+		// Pass most of the time, so the bidding finishes
+		bids := higherBids(r.View.Bids)
+		if len(bids) == 0 || p.Tape.Intn(3) < 2 {
+			return player.BidResponse{Bid: game.Pass{}}, nil
+		}
+		return player.BidResponse{Bid: bids[p.Tape.Intn(len(bids))]}, nil
+
+	case player.DiscardRequest:
+		hand := p.hand.Copy()
+		discards := c.NewList[card.Card](3)
+		for i := 0; i < 3; i++ {
+			discards.Append(util.E(hand.Remove(p.Tape.Intn(hand.Size()))))
+		}
+		return player.DiscardResponse{Cards: discards}, nil
+
+	case player.PlayRequest:
+		if p.Tape.Intn(50) == 49 {
+			return player.TakebackResponse{}, nil
+		}
+		return player.PlayResponse{Index: util.E(r.ValidPlays.Get(p.Tape.Intn(r.ValidPlays.Size())))}, nil
+
+	case player.JokerSuitRequest:
+		suits := []card.Suit{card.Spades, card.Clubs, card.Diamonds, card.Hearts}
+		return player.JokerSuitResponse{Suit: suits[p.Tape.Intn(4)]}, nil
+
+	case player.AllowTakebackRequest:
+		return player.AllowTakebackResponse{Allow: p.Tape.Intn(2) == 0}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported request %T", r)
+	}
+}
+
+// higherBids returns all the bids which beat the bids made so far.
+func higherBids(history []game.BidInfo) []game.Bid {
+	highest := 0
+	for _, b := range history {
+		if (b.Bid != game.Pass{}) {
+			highest = b.Bid.Value()
+		}
+	}
+
+	var bids []game.Bid
+	for _, b := range game.AllBids() {
+		if b.Value() > highest {
+			bids = append(bids, b)
+		}
+	}
+	return bids
+}
+
+// DefaultInvariants are the invariants checked by Simulate by default.
+var DefaultInvariants = []Invariant{
+	{"cards conserved", checkCardsConserved},
+	{"hands sorted without duplicates", checkHandsSorted},
+	{"plays legal", checkPlaysLegal},
+	{"tricks complete", checkTricks},
+}
+
+// checkCardsConserved checks that all 43 cards are accounted for.
+func checkCardsConserved(ct *Controller) error {
+	seen := map[card.Card]int{}
+	for _, hand := range ct.hands {
+		if hand == nil {
+			continue
+		}
+		for _, cd := range *hand {
+			seen[cd]++
+		}
+	}
+	// The kitty is in the contractor's hand once bidding is finished
+	if ct.bid == nil && ct.kitty != nil {
+		for _, cd := range *ct.kitty {
+			seen[cd]++
+		}
+	}
+	if ct.discards != nil {
+		for _, cd := range *ct.discards {
+			seen[cd]++
+		}
+	}
+	for _, t := range ct.trickHistory {
+		if t.plays == nil {
+			continue
+		}
+		for _, pl := range *t.plays {
+			seen[pl.Card]++
+		}
+	}
+
+	if ct.hands[0] == nil {
+		// Cards not dealt yet
+		return nil
+	}
+	for _, cd := range *game.GetDeck() {
+		if seen[cd] != 1 {
+			return fmt.Errorf("card %s appears %d times", cd, seen[cd])
+		}
+		delete(seen, cd)
+	}
+	for cd := range seen {
+		return fmt.Errorf("unknown card %v", cd)
+	}
+	return nil
+}
+
+// checkHandsSorted checks that each hand is sorted and has no duplicates.
+func checkHandsSorted(ct *Controller) error {
+	var sorter game.Bid = game.NoTrumpsBid{}
+	if ct.bid != nil {
+		sorter = ct.bid
+	}
+
+	for i, hand := range ct.hands {
+		if hand == nil || hand.Size() == 0 {
+			continue
+		}
+		seen := c.NewSet[card.Card](hand.Size())
+		for _, cd := range *hand {
+			if seen.Contains(cd) {
+				return fmt.Errorf("player %d has %s twice", i, cd)
+			}
+			seen.Add(cd)
+		}
+
+		sorted := hand.Copy()
+		sorter.SortHand(sorted)
+		for j := range *hand {
+			if (*hand)[j] != (*sorted)[j] {
+				return fmt.Errorf("player %d's hand %v is not sorted", i, *hand)
+			}
+		}
+	}
+	return nil
+}
+
+// checkPlaysLegal replays the hand from the deal, checking that each card
+// played was valid.
+func checkPlaysLegal(ct *Controller) error {
+	if ct.bid == nil || ct.discards == nil {
+		return nil
+	}
+
+	// Reconstruct the hands after the kitty
+	var hands [4]*c.List[card.Card]
+	for i := range hands {
+		hands[i] = util.E(ct.deck.CopyPart(i*10, i*10+10))
+	}
+	hands[ct.contractor].Append(*ct.kitty...)
+	hands[ct.contractor] = hands[ct.contractor].Filter(func(_ int, cd card.Card) bool {
+		return !ct.discards.Contains(cd)
+	})
+
+	// The Joker's suit is only named when it is led
+	bid := ct.contract()
+	for n, t := range ct.trickHistory {
+		if t.plays == nil {
+			break
+		}
+		trick := c.NewList[game.PlayInfo](4)
+		for _, pl := range *t.plays {
+			hand := hands[pl.Player]
+			j, err := hand.Find(pl.Card)
+			if err != nil {
+				return fmt.Errorf("trick %d: player %d played %s, which they don't hold", n, pl.Player, pl.Card)
+			}
+			if trick.Size() == 0 && pl.Card == card.JokerCard {
+				bid = ct.bid
+			}
+			if !bid.ValidPlays(trick, hand).Contains(j) {
+				return fmt.Errorf("trick %d: player %d played %s, which is invalid", n, pl.Player, pl.Card)
+			}
+			util.E(hand.Remove(j))
+			trick.Append(pl)
+		}
+	}
+	return nil
+}
+
+// checkTricks checks that each completed trick is valid, and that there are
+// 10 tricks once the hand is finished.
+func checkTricks(ct *Controller) error {
+	_, misere := ct.bid.(game.MisereBid)
+	playersPerTrick := 4
+	if misere {
+		playersPerTrick = 3
+	}
+
+	for n := 0; n < ct.tricksPlayed; n++ {
+		t := ct.trickHistory[n]
+		if t.plays.Size() != playersPerTrick {
+			return fmt.Errorf("trick %d has %d plays", n, t.plays.Size())
+		}
+		winnerPlayed := false
+		for _, pl := range *t.plays {
+			if misere && pl.Player == (ct.contractor+2)%4 {
+				return fmt.Errorf("trick %d: contractor's partner played in misere", n)
+			}
+			if pl.Player == t.winner {
+				winnerPlayed = true
+			}
+		}
+		if !winnerPlayed {
+			return fmt.Errorf("trick %d: winner %d didn't play", n, t.winner)
+		}
+	}
+
+	switch ct.result.(type) {
+	case game.BidWon, game.BidLost:
+		if ct.tricksPlayed != 10 {
+			return fmt.Errorf("hand finished after %d tricks", ct.tricksPlayed)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/player"
+	"github.com/stretchr/testify/assert"
+)
+
+var simGames = flag.Int("sim.games", 500, "number of games to play in TestSimulate")
+
+func TestSimulate(t *testing.T) {
+	f := Simulate(SimConfig{Games: *simGames, Seed: 1})
+	if f != nil {
+		t.Fatalf("simulation failed: %s", f)
+	}
+}
+
+func TestSimulateNoTakebacks(t *testing.T) {
+	f := Simulate(SimConfig{Games: *simGames / 5, Seed: 1, Rules: game.HouseRules{NoTakebacks: true}})
+	if f != nil {
+		t.Fatalf("simulation failed: %s", f)
+	}
+}
+
+func TestSimulateShrinks(t *testing.T) {
+	// Fails whenever bidding finishes with a contract
+	noContract := Invariant{"no contract", func(ct *Controller) error {
+		if ct.bid != nil {
+			return fmt.Errorf("contract %s", ct.bid)
+		}
+		return nil
+	}}
+
+	f := Simulate(SimConfig{Games: 100, Seed: 1, Invariants: []Invariant{noContract}})
+	if assert.NotNil(t, f) {
+		var ie *InvariantError
+		assert.ErrorAs(t, f.Err, &ie)
+		assert.Equal(t, "no contract", ie.Invariant)
+		// The shrunk failure reproduces
+		assert.Error(t, Replay(SimConfig{Invariants: []Invariant{noContract}}, f.Seed, f.Choices))
+		// The shortest way to a contract: player 0 doesn't take back, then
+		// bids the lowest bid, and everyone else passes
+		assert.Equal(t, []int{0, 2}, f.Choices)
+	}
+}
+
+func TestSimulateHeuristic(t *testing.T) {
+	f := Simulate(SimConfig{
+		Games: *simGames / 5,
+		Seed:  1,
+		Bots: func(*Tape) (players [4]player.PlayerV2) {
+			for i := range players {
+				players[i] = player.Adapt(&player.HeuristicPlayer{})
+			}
+			return
+		},
+	})
+	if f != nil {
+		t.Fatalf("simulation failed: %s", f)
+	}
+}
+
+func TestSimulatePIMC(t *testing.T) {
+	f := Simulate(SimConfig{
+		Games: 2,
+		Seed:  1,
+		Bots: func(*Tape) (players [4]player.PlayerV2) {
+			for i := range players {
+				players[i] = player.Adapt(&player.PIMCPlayer{Samples: 2, Rand: rand.New(rand.NewSource(int64(i)))})
+			}
+			return
+		},
+	})
+	if f != nil {
+		t.Fatalf("simulation failed: %s", f)
+	}
+}
+
+func TestSimulateISMCTS(t *testing.T) {
+	f := Simulate(SimConfig{
+		Games: 5,
+		Seed:  1,
+		Bots: func(*Tape) (players [4]player.PlayerV2) {
+			for i := range players {
+				players[i] = player.Adapt(&player.ISMCTSPlayer{Iterations: 50, Rand: rand.New(rand.NewSource(int64(i)))})
+			}
+			return
+		},
+	})
+	if f != nil {
+		t.Fatalf("simulation failed: %s", f)
+	}
+}
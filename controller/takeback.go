@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/barrettj12/500/player"
+)
+
+// decision records a player's (valid) response to a request, so that it can
+// be replayed after a takeback.
+type decision struct {
+	player int
+	resp   player.Response
+}
+
+// takeback is used to unwind playHand when a player takes back their last bid
+// or card.
+type takeback struct{}
+
+// ask sends the request to player i until they give a valid response, and
+// returns that response, which must be of type R. If the hand is being
+// replayed after a takeback, the recorded response is returned instead.
+func ask[R player.Response](ctx context.Context, ct *Controller, i int, req player.Request, valid func(R) bool) R {
+	if ct.replayPos < len(ct.decisions) {
+		d := ct.decisions[ct.replayPos]
+		ct.replayPos++
+		return d.resp.(R)
+	}
+	if ct.replaying {
+		// Replay is finished - we're now asking for new decisions
+		ct.replaying = false
+		ct.notifyAll(ctx, player.ReplayDoneEvent{})
+	}
+
+	r := retryTillValid(func() (R, bool) {
+		r, ok := decide[R](ctx, ct, i, req)
+		return r, ok && valid(r)
+	})
+	ct.decisions = append(ct.decisions, decision{i, r})
+	ct.replayPos++
+	return r
+}
+
+// decide sends the given request to a player, and returns their response,
+// which must be of type R. It returns false if the player responded with
+// something else (e.g. a takeback which was refused).
+func decide[R player.Response](ctx context.Context, ct *Controller, i int, req player.Request) (R, bool) {
+	var zero R
+	resp, err := ct.timedDecide(ctx, i, req)
+	if err != nil {
+		panic(abort{fmt.Errorf("requesting %T from player %d: %w", req, i, err)})
+	}
+
+	if _, ok := resp.(player.TakebackResponse); ok {
+		switch req.(type) {
+		case player.BidRequest, player.PlayRequest:
+			ct.takeback(ctx, i)
+		}
+		// Takeback was refused or not allowed for this request
+		ct.notify(ctx, i, player.TakebackRefusedEvent{})
+		return zero, false
+	}
+
+	r, ok := resp.(R)
+	if !ok {
+		panic(abort{fmt.Errorf("player %d answered %T with %T", i, req, resp)})
+	}
+	return r, true
+}
+
+// takeback rewinds the hand to before player i's last bid or card. The
+// takeback is refused (and takeback returns normally) if takebacks are
+// disabled by the house rules, if the player hasn't bid or played a card yet,
+// or if an opponent objects. Otherwise, takeback doesn't return - it unwinds
+// playHand so that the hand can be replayed.
+func (ct *Controller) takeback(ctx context.Context, i int) {
+	if ct.Rules.NoTakebacks {
+		return
+	}
+
+	last := -1
+	for j, d := range ct.decisions {
+		if d.player != i {
+			continue
+		}
+		switch d.resp.(type) {
+		case player.BidResponse, player.PlayResponse:
+			last = j
+		}
+	}
+	if last == -1 {
+		return
+	}
+
+	for _, opp := range []int{(i + 1) % 4, (i + 3) % 4} {
+		allowed, ok := decide[player.AllowTakebackResponse](ctx, ct, opp, player.AllowTakebackRequest{
+			Player: i,
+			View:   ct.view(),
+		})
+		if !ok || !allowed.Allow {
+			return
+		}
+	}
+
+	ct.decisions = ct.decisions[:last]
+	ct.replaying = true
+	ct.notifyAll(ctx, player.TakebackEvent{Player: i})
+	panic(takeback{})
+}
@@ -2,6 +2,7 @@ package game
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/barrettj12/500/card"
 	"github.com/barrettj12/500/util"
@@ -336,3 +337,21 @@ func (p Pass) ValidPlays(trick *c.List[PlayInfo], hand *c.List[card.Card]) *c.Li
 }
 func (p Pass) SortHand(*c.List[card.Card]) { panic("Pass.SortHand unimplemented") }
 func (p Pass) Won(tricksWon int) bool      { panic("Pass.Won unimplemented") }
+
+// AllBids returns every possible bid (except Pass), in increasing order of
+// value.
+func AllBids() []Bid {
+	var bids []Bid
+	for tricks := 6; tricks <= 10; tricks++ {
+		for _, suit := range []card.Suit{card.Spades, card.Clubs, card.Diamonds, card.Hearts} {
+			bids = append(bids, SuitBid{tricks, suit})
+		}
+		bids = append(bids, NoTrumpsBid{Tricks: tricks})
+	}
+	bids = append(bids, MisereBid{}, MisereBid{Open: true})
+
+	sort.SliceStable(bids, func(i, j int) bool {
+		return bids[i].Value() < bids[j].Value()
+	})
+	return bids
+}
@@ -63,3 +63,74 @@ func (r BidLost) Info() string {
 	return fmt.Sprintf("Contractors lost their bid of %s with %d tricks",
 		r.Bid, r.Tricks)
 }
+
+// Forfeit says that a player forfeited the hand, by running out of time.
+type Forfeit struct {
+	Player int
+	// Bid is the contract, or nil if bidding hadn't finished.
+	Bid Bid
+}
+
+func (r Forfeit) Info() string {
+	return fmt.Sprintf("Player %d forfeited the hand by running out of time", r.Player)
+}
+
+// HandRecord keeps a record of what happened in a hand, so it can be reviewed
+// after the hand is finished.
+type HandRecord struct {
+	// Hands holds the cards dealt to each player.
+	Hands      [4]*c.List[card.Card]
+	Bid        Bid
+	Contractor int
+	// Kitty holds the three cards dealt to the kitty.
+	Kitty *c.List[card.Card]
+	// Discards holds the three cards the contractor discarded after picking
+	// up the kitty.
+	Discards *c.List[card.Card]
+	// Tricks holds the tricks played.
+	Tricks []Trick
+	Result HandResult
+}
+
+// Score returns the points scored by each team for the given hand result.
+// Team 0 is players 0 and 2, team 1 is players 1 and 3.
+//
+// The contractors score the value of their bid if they win it, and lose that
+// value otherwise. The defenders score 10 points for each trick they win
+// (except in misere, where they score nothing).
+//
+// If a player forfeits the hand, their team loses the value of the contract
+// (or of the lowest bid, 6♠, if bidding hadn't finished).
+func Score(res HandResult, contractor int) [2]int {
+	var scores [2]int
+	var bid Bid
+	var tricks int
+	switch r := res.(type) {
+	case Forfeit:
+		value := SuitBid{6, card.Spades}.Value()
+		if r.Bid != nil {
+			value = r.Bid.Value()
+		}
+		scores[r.Player%2] -= value
+		return scores
+	case BidWon:
+		scores[contractor%2] += r.Bid.Value()
+		bid, tricks = r.Bid, r.Tricks
+	case BidLost:
+		scores[contractor%2] -= r.Bid.Value()
+		bid, tricks = r.Bid, r.Tricks
+	default:
+		return scores
+	}
+
+	if _, ok := bid.(MisereBid); !ok {
+		scores[(contractor+1)%2] += 10 * (10 - tricks)
+	}
+	return scores
+}
+
+// HouseRules holds optional rules which vary from table to table.
+type HouseRules struct {
+	// NoTakebacks stops players from taking back their last bid or card.
+	NoTakebacks bool
+}
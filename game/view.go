@@ -0,0 +1,44 @@
+package game
+
+import (
+	"github.com/barrettj12/500/card"
+
+	c "github.com/barrettj12/collections"
+)
+
+// View is a read-only view of the public state of the game, which is passed
+// to players with each request.
+type View struct {
+	// Bids holds every bid (and pass) made so far in this hand.
+	Bids []BidInfo
+	// Bid is the contract, or nil if bidding hasn't finished.
+	Bid Bid
+	// Contractor is the player who won the bidding, or -1 if bidding hasn't
+	// finished.
+	Contractor int
+	// Tricks holds the completed tricks in this hand.
+	Tricks []Trick
+	// CardsLeft is the number of cards in each player's hand.
+	CardsLeft [4]int
+	// Exposed holds the hands which are visible to all players (e.g. the
+	// contractor's hand in open misere). It is nil for hidden hands.
+	Exposed [4]*c.List[card.Card]
+	// Score is the match score for each team. Team 0 is players 0 and 2,
+	// team 1 is players 1 and 3.
+	Score [2]int
+	// Rules are the house rules for the game.
+	Rules HouseRules
+}
+
+// BidInfo holds information about a bid.
+type BidInfo struct {
+	Player int
+	Bid    Bid
+}
+
+// Trick holds information about a completed trick.
+type Trick struct {
+	Leader int
+	Plays  *c.List[PlayInfo]
+	Winner int
+}
@@ -1,25 +1,259 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
 	"math/rand"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/barrettj12/500/controller"
+	"github.com/barrettj12/500/game"
 	"github.com/barrettj12/500/player"
+	"github.com/barrettj12/500/remote"
+	"github.com/barrettj12/500/review"
+	"github.com/barrettj12/500/util"
 )
 
+// seatNames are the names of the seats, in player order. Partners sit
+// opposite each other.
+var seatNames = [4]string{"north", "east", "south", "west"}
+
 func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
 func main() {
-	ct := controller.Controller{
-		Players: [4]player.Player{
-			&player.HumanPlayer{},
-			&player.RandomPlayer{Delay: player.SLEEP},
-			&player.RandomPlayer{Delay: player.SLEEP},
-			&player.RandomPlayer{Delay: player.SLEEP},
-		},
-	}
-	ct.Play()
+	humans := flag.Int("humans", 1, "number of human players sharing this terminal (0-4)")
+	pacing := flag.String("pacing", "human", `pauses during the game: "human" or "instant"`)
+	var seats [4]*string
+	for i, name := range seatNames {
+		seats[i] = flag.String(name, "", fmt.Sprintf(
+			`who sits %s: "human", "bot:LEVEL", "remote:HOST:PORT" or "exec:COMMAND" (default from -humans)
+LEVEL is one of: %s`, name, strings.Join(player.LevelNames(), ", ")))
+	}
+	weightsPath := flag.String("weights", "", "file of weights for the bots, as written by cmd/tune")
+	recordPath := flag.String("record", "", "file to append the human players' decisions to, as JSON lines")
+	reviewPath := flag.String("review", "", "file to append the solver's review of the hand to, as JSON lines")
+	advisor := flag.String("advisor", "heuristic", `bot giving hints to human players: "heuristic", "simulation" or "none"`)
+	debug := flag.Bool("debug", false, "show why the bots made their decisions below the board")
+	execLogPath := flag.String("exec-log", "", "file to append the lines exchanged with exec: players to")
+	flag.Parse()
+
+	ct := controller.Controller{StatePath: ".gamestate.log"}
+	switch *pacing {
+	case "human":
+		ct.Pacing = player.HumanPacing
+	case "instant":
+		ct.Pacing = player.Instant
+	default:
+		fmt.Fprintf(os.Stderr, "unknown pacing %q\n", *pacing)
+		os.Exit(2)
+	}
+
+	var weights *player.Weights
+	if *weightsPath != "" {
+		w, err := player.LoadWeights(*weightsPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "loading weights: %v\n", err)
+			os.Exit(2)
+		}
+		weights = w
+	}
+
+	specs := [4]string{}
+	humanSeats := 0
+	for i := range specs {
+		specs[i] = *seats[i]
+		if specs[i] == "" {
+			specs[i] = "bot:medium"
+			if i < *humans {
+				specs[i] = "human"
+			}
+		}
+		if specs[i] == "human" {
+			humanSeats++
+		}
+	}
+
+	opts := seatOptions{weights: weights, advisor: *advisor}
+	if _, err := opts.newAdvisor(); err != nil {
+		fmt.Fprintf(os.Stderr, "-advisor: %v\n", err)
+		os.Exit(2)
+	}
+	// Several humans on one terminal play in hot-seat mode
+	if humanSeats > 1 {
+		opts.hotSeat = &player.HotSeat{}
+	}
+	if *debug {
+		opts.debug = &player.DebugPane{}
+	}
+
+	if *recordPath != "" {
+		file, err := os.OpenFile(*recordPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "opening record file: %v\n", err)
+			os.Exit(2)
+		}
+		defer file.Close()
+		opts.record = file
+		opts.game = time.Now().Format(time.RFC3339)
+	}
+
+	var execLog io.Writer
+	if *execLogPath != "" {
+		file, err := os.OpenFile(*execLogPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "opening exec log: %v\n", err)
+			os.Exit(2)
+		}
+		defer file.Close()
+		execLog = file
+	}
+
+	for i, spec := range specs {
+		p, err := newPlayer(spec, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "-%s: %v\n", seatNames[i], err)
+			os.Exit(2)
+		}
+		ct.Players[i] = p
+		if e, ok := p.(*player.ExecPlayer); ok {
+			if execLog != nil {
+				e.Log = seatWriter{execLog, seatNames[i]}
+			}
+			defer e.Close()
+		}
+	}
+	util.E0(ct.Play(context.Background()))
+	reviewHand(ct.Record(), humanSeats > 0, *reviewPath)
+}
+
+// seatWriter marks each line written to w with the seat it came from.
+type seatWriter struct {
+	w    io.Writer
+	seat string
+}
+
+func (s seatWriter) Write(p []byte) (int, error) {
+	if _, err := fmt.Fprintf(s.w, "%s %s", s.seat, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// reviewHand offers the human players a review of the hand, and appends it
+// to the file at path if set.
+func reviewHand(rec game.HandRecord, offer bool, path string) {
+	if offer {
+		fmt.Print("Review the hand with the solver? [y/n]: ")
+		offer = player.ReadLine() == "y"
+	}
+	if !offer && path == "" {
+		return
+	}
+
+	rv, err := review.Hand(rec)
+	if err != nil {
+		if offer {
+			fmt.Printf("Can't review the hand: %v\n", err)
+		}
+		return
+	}
+	if offer {
+		var names [4]string
+		for i, n := range seatNames {
+			names[i] = strings.ToUpper(n[:1]) + n[1:]
+		}
+		util.E0(rv.Write(os.Stdout, names, true))
+	}
+	if path != "" {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "saving review: %v\n", err)
+			return
+		}
+		defer file.Close()
+		if err := json.NewEncoder(file).Encode(rv); err != nil {
+			fmt.Fprintf(os.Stderr, "saving review: %v\n", err)
+		}
+	}
+}
+
+// seatOptions are the options used to create each player.
+type seatOptions struct {
+	hotSeat *player.HotSeat
+	weights *player.Weights
+	advisor string
+	// If record is set, human players' decisions are logged to it, marked
+	// with the game.
+	record io.Writer
+	game   string
+	// If debug is set, bots' explanations are shown to human players.
+	debug *player.DebugPane
+}
+
+// newAdvisor returns a bot to give hints to a human player, or nil if hints
+// are turned off.
+func (o seatOptions) newAdvisor() (player.Advisor, error) {
+	h := player.HeuristicPlayer{Weights: o.weights, Table: player.DefaultBidTable()}
+	switch o.advisor {
+	case "none":
+		return nil, nil
+	case "heuristic":
+		return &h, nil
+	case "simulation":
+		return &player.PIMCPlayer{HeuristicPlayer: h, Samples: 20, TimeBudget: 2 * time.Second}, nil
+	}
+	return nil, fmt.Errorf("unknown advisor %q", o.advisor)
+}
+
+// newPlayer creates the player described by a seat flag.
+func newPlayer(spec string, opts seatOptions) (player.PlayerV2, error) {
+	kind, arg, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "human":
+		advisor, err := opts.newAdvisor()
+		if err != nil {
+			return nil, err
+		}
+		var p player.Player = &player.HumanPlayer{HotSeat: opts.hotSeat, Advisor: advisor, Debug: opts.debug}
+		if opts.record != nil {
+			p = &player.DecisionLogger{Player: p, W: opts.record, Game: opts.game}
+		}
+		return player.Adapt(p), nil
+	case "bot":
+		if arg == "" {
+			arg = "medium"
+		}
+		bot, err := player.NewBot(arg, nil, opts.weights)
+		if err != nil {
+			return nil, err
+		}
+		p := player.Adapt(bot)
+		if opts.debug != nil {
+			p = player.Explained(p, opts.debug.Add)
+		}
+		return p, nil
+	case "remote":
+		if arg == "" {
+			return nil, fmt.Errorf("no address given for remote player")
+		}
+		rp, err := remote.Dial(arg)
+		if err != nil {
+			return nil, err
+		}
+		return player.Adapt(rp), nil
+	case "exec":
+		args := strings.Fields(arg)
+		if len(args) == 0 {
+			return nil, fmt.Errorf("no command given for exec player")
+		}
+		return &player.ExecPlayer{Path: args[0], Args: args[1:], Stderr: os.Stderr}, nil
+	}
+	return nil, fmt.Errorf("unknown player %q", spec)
 }
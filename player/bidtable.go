@@ -0,0 +1,234 @@
+package player
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/util"
+
+	c "github.com/barrettj12/collections"
+)
+
+// HandFeatures summarises a hand for a denomination. For no trumps, Trumps
+// is the length of the longest suit, and there are no bowers.
+type HandFeatures struct {
+	Trumps int // number of trumps, including the bowers and Joker
+	Bowers int
+	Joker  int
+	Aces   int // side-suit aces (all aces in no trumps)
+	Voids  int // side suits with no cards (all suits in no trumps)
+}
+
+// Features returns the features of the hand, if it wins the contract in the
+// given denomination (a SuitBid or NoTrumpsBid).
+func Features(hand *c.List[card.Card], bid game.Bid) HandFeatures {
+	var f HandFeatures
+	if hand.Contains(card.JokerCard) {
+		f.Joker = 1
+	}
+	bySuit := splitSuits(hand, bid)
+	trumps := card.NoSuit
+	if b, ok := bid.(game.SuitBid); ok {
+		trumps = b.TrumpSuit
+		f.Trumps = len(bySuit[trumps])
+		for _, cd := range bySuit[trumps] {
+			if cd.Rank == card.Jack {
+				f.Bowers++
+			}
+		}
+	}
+
+	for _, s := range suits {
+		if s == trumps {
+			continue
+		}
+		cards := bySuit[s]
+		if trumps == card.NoSuit && len(cards) > f.Trumps {
+			f.Trumps = len(cards)
+		}
+		if containsRank(cards, card.Ace) {
+			f.Aces++
+		}
+		if len(cards) == 0 {
+			f.Voids++
+		}
+	}
+	return f
+}
+
+// BidTable records the average number of tricks taken by the contractor's
+// side, for hands with each set of features. It is generated by simulation
+// (see cmd/bidtable), and used to estimate what a hand is worth.
+type BidTable struct {
+	entries map[tableKey]*tableEntry
+}
+
+type tableKey struct {
+	NoTrumps bool
+	HandFeatures
+}
+
+type tableEntry struct {
+	count  int
+	tricks float64 // total
+}
+
+// NewBidTable returns an empty table.
+func NewBidTable() *BidTable {
+	return &BidTable{entries: map[tableKey]*tableEntry{}}
+}
+
+//go:embed bidtable.txt
+var defaultBidTable string
+
+var (
+	loadDefaultBidTable sync.Once
+	defaultTable        *BidTable
+)
+
+// DefaultBidTable returns the table built into the program.
+func DefaultBidTable() *BidTable {
+	loadDefaultBidTable.Do(func() {
+		defaultTable = util.E(ReadBidTable(strings.NewReader(defaultBidTable)))
+	})
+	return defaultTable
+}
+
+// Add records that a hand with the given features took the given number of
+// tricks, in the given denomination.
+func (t *BidTable) Add(bid game.Bid, f HandFeatures, tricks int) {
+	_, nt := bid.(game.NoTrumpsBid)
+	k := tableKey{nt, f}
+	e, ok := t.entries[k]
+	if !ok {
+		e = &tableEntry{}
+		t.entries[k] = e
+	}
+	e.count++
+	e.tricks += float64(tricks)
+}
+
+// minSamples is the number of hands needed for an average to be used.
+const minSamples = 30
+
+// ExpectedTricks estimates the number of tricks the hand will take if it
+// wins the contract in the given denomination (a SuitBid or NoTrumpsBid),
+// including the support expected from the kitty and partner.
+//
+// If there are too few similar hands in the table, the least important
+// features are ignored, one at a time, until there are enough.
+func (t *BidTable) ExpectedTricks(hand *c.List[card.Card], bid game.Bid) float64 {
+	_, nt := bid.(game.NoTrumpsBid)
+	want := tableKey{nt, Features(hand, bid)}
+
+	// Features to match, from most to least important
+	matches := []func(k tableKey) bool{
+		func(k tableKey) bool { return k.NoTrumps == want.NoTrumps },
+		func(k tableKey) bool { return k.Trumps == want.Trumps },
+		func(k tableKey) bool { return k.Bowers == want.Bowers },
+		func(k tableKey) bool { return k.Joker == want.Joker },
+		func(k tableKey) bool { return k.Aces == want.Aces },
+		func(k tableKey) bool { return k.Voids == want.Voids },
+	}
+	for n := len(matches); n > 0; n-- {
+		count, tricks := 0, 0.0
+		for k, e := range t.entries {
+			match := true
+			for _, m := range matches[:n] {
+				match = match && m(k)
+			}
+			if match {
+				count += e.count
+				tricks += e.tricks
+			}
+		}
+		if count >= minSamples || (n == 1 && count > 0) {
+			return tricks / float64(count)
+		}
+	}
+	return 0
+}
+
+// Advise picks a bid for the hand using the table's expected tricks, in the
+// same way as ChooseBid. The weights are used for everything but estimating
+// tricks.
+func (t *BidTable) Advise(hand *c.List[card.Card], w Weights, highBid game.Bid, partnerHigh bool, partnerBid game.Bid) game.Bid {
+	return ChooseBid(hand, w, t.ExpectedTricks, highBid, partnerHigh, partnerBid)
+}
+
+// Write writes the table in the text format read by ReadBidTable: one line
+// per set of features, giving the denomination ("suit" or "nt"), the
+// features, the number of hands, and the average number of tricks.
+func (t *BidTable) Write(w io.Writer) error {
+	keys := make([]tableKey, 0, len(t.entries))
+	for k := range t.entries {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.NoTrumps != b.NoTrumps {
+			return !a.NoTrumps
+		}
+		for _, d := range [][2]int{
+			{a.Trumps, b.Trumps}, {a.Bowers, b.Bowers}, {a.Joker, b.Joker}, {a.Aces, b.Aces}, {a.Voids, b.Voids},
+		} {
+			if d[0] != d[1] {
+				return d[0] < d[1]
+			}
+		}
+		return false
+	})
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# denomination trumps bowers joker aces voids hands tricks")
+	for _, k := range keys {
+		e := t.entries[k]
+		denom := "suit"
+		if k.NoTrumps {
+			denom = "nt"
+		}
+		fmt.Fprintf(bw, "%s %d %d %d %d %d %d %.2f\n", denom,
+			k.Trumps, k.Bowers, k.Joker, k.Aces, k.Voids, e.count, e.tricks/float64(e.count))
+	}
+	return bw.Flush()
+}
+
+// ReadBidTable reads a table written by BidTable.Write.
+func ReadBidTable(r io.Reader) (*BidTable, error) {
+	t := NewBidTable()
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		var (
+			denom string
+			k     tableKey
+			e     tableEntry
+			avg   float64
+		)
+		_, err := fmt.Sscanf(text, "%s %d %d %d %d %d %d %f", &denom,
+			&k.Trumps, &k.Bowers, &k.Joker, &k.Aces, &k.Voids, &e.count, &avg)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		switch denom {
+		case "suit":
+		case "nt":
+			k.NoTrumps = true
+		default:
+			return nil, fmt.Errorf("line %d: unknown denomination %q", line, denom)
+		}
+		e.tricks = avg * float64(e.count)
+		t.entries[k] = &e
+	}
+	return t, scanner.Err()
+}
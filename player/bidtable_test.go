@@ -0,0 +1,79 @@
+package player
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatures(t *testing.T) {
+	h := hand(
+		card.JokerCard, card.Card{card.Jack, card.Hearts}, card.Card{card.Jack, card.Diamonds},
+		card.Card{card.Ace, card.Hearts}, card.Card{9, card.Hearts}, card.Card{7, card.Hearts},
+		card.Card{card.Ace, card.Spades}, card.Card{5, card.Spades},
+		card.Card{card.Ace, card.Diamonds}, card.Card{8, card.Diamonds},
+	)
+	assert.Equal(t, HandFeatures{Trumps: 6, Bowers: 2, Joker: 1, Aces: 2, Voids: 1},
+		Features(h, game.SuitBid{6, card.Hearts}))
+	assert.Equal(t, HandFeatures{Trumps: 5, Bowers: 2, Joker: 1, Aces: 2, Voids: 1},
+		Features(h, game.SuitBid{6, card.Diamonds}))
+	assert.Equal(t, HandFeatures{Trumps: 4, Joker: 1, Aces: 3, Voids: 1},
+		Features(h, game.NoTrumpsBid{}))
+}
+
+func TestBidTable(t *testing.T) {
+	table := NewBidTable()
+	strong := HandFeatures{Trumps: 6, Bowers: 2, Joker: 1, Aces: 1}
+	for i := 0; i < minSamples; i++ {
+		table.Add(game.SuitBid{6, card.Hearts}, strong, 8)
+		table.Add(game.SuitBid{6, card.Hearts}, HandFeatures{Trumps: 2}, 4)
+	}
+	table.Add(game.SuitBid{6, card.Spades}, HandFeatures{Trumps: 6, Bowers: 2, Joker: 1, Aces: 1, Voids: 2}, 10)
+
+	// Round trip through the file format
+	var sb strings.Builder
+	assert.NoError(t, table.Write(&sb))
+	read, err := ReadBidTable(strings.NewReader(sb.String()))
+	assert.NoError(t, err)
+	assert.Equal(t, table, read)
+
+	h := hand(
+		card.JokerCard, card.Card{card.Jack, card.Hearts}, card.Card{card.Jack, card.Diamonds},
+		card.Card{card.Ace, card.Hearts}, card.Card{9, card.Hearts}, card.Card{7, card.Hearts},
+		card.Card{card.Ace, card.Spades}, card.Card{5, card.Spades},
+		card.Card{6, card.Clubs}, card.Card{8, card.Diamonds},
+	)
+	assert.Equal(t, strong, Features(h, game.SuitBid{6, card.Hearts}))
+	assert.Equal(t, 8.0, table.ExpectedTricks(h, game.SuitBid{6, card.Hearts}))
+	assert.Equal(t, game.SuitBid{6, card.Hearts}, table.Advise(h, DefaultWeights, nil, false, nil))
+	assert.Equal(t, game.SuitBid{8, card.Hearts}, table.Advise(h, DefaultWeights, game.SuitBid{7, card.Hearts}, false, nil))
+	w := DefaultWeights
+	w.MisereRisk = 10
+	assert.Equal(t, game.MisereBid{Open: true}, table.Advise(h, w, nil, false, nil))
+
+	// Too few hands with two voids, so the voids are ignored
+	h = hand(
+		card.JokerCard, card.Card{card.Jack, card.Hearts}, card.Card{card.Jack, card.Diamonds},
+		card.Card{card.Ace, card.Hearts}, card.Card{9, card.Hearts}, card.Card{7, card.Hearts},
+		card.Card{card.Ace, card.Spades}, card.Card{5, card.Spades},
+		card.Card{6, card.Spades}, card.Card{8, card.Spades},
+	)
+	assert.Equal(t, 2, Features(h, game.SuitBid{6, card.Hearts}).Voids)
+	assert.InDelta(t, (8*30+10)/31.0, table.ExpectedTricks(h, game.SuitBid{6, card.Hearts}), 0.01)
+}
+
+func TestDefaultBidTable(t *testing.T) {
+	table := DefaultBidTable()
+	strong := hand(
+		card.JokerCard, card.Card{card.Jack, card.Hearts}, card.Card{card.Jack, card.Diamonds},
+		card.Card{card.Ace, card.Hearts}, card.Card{9, card.Hearts}, card.Card{7, card.Hearts},
+		card.Card{card.Ace, card.Spades}, card.Card{5, card.Spades},
+		card.Card{6, card.Clubs}, card.Card{8, card.Diamonds},
+	)
+	bid := game.SuitBid{6, card.Hearts}
+	assert.Greater(t, table.ExpectedTricks(strong, bid), 7.0)
+	assert.Less(t, table.ExpectedTricks(strong, game.SuitBid{6, card.Clubs}), table.ExpectedTricks(strong, bid))
+}
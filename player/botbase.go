@@ -0,0 +1,162 @@
+package player
+
+import (
+	"time"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+
+	c "github.com/barrettj12/collections"
+)
+
+// BotBase keeps track of everything a player can observe during a hand. It is
+// meant to be embedded in computer players, which then only need to implement
+// the requests: Bid, Discard, Play and JokerSuit.
+//
+// The state is reset by NotifyPlayerNum, which the controller sends at the
+// start of each hand (and again when a hand is replayed after a takeback).
+// A bot which overrides a Notify method should call the BotBase's method too.
+type BotBase struct {
+	Seat int
+	Hand *c.List[card.Card]
+
+	// Auction state
+	// Bids holds every bid made by each player, including passes.
+	Bids       [4][]game.Bid
+	HighBid    game.Bid // nil if no-one has bid
+	HighBidder int
+
+	// Contract, once bidding is finished
+	Contract   game.Bid
+	Contractor int
+	// Kitty holds the kitty cards, if this player picked them up.
+	Kitty *c.List[card.Card]
+	// Discards holds the cards this player discarded, if they are the
+	// contractor.
+	Discards *c.List[card.Card]
+
+	// Card play state
+	// Tricks holds the completed tricks.
+	Tricks    [][]game.PlayInfo
+	Trick     []game.PlayInfo
+	Leader    int    // leader of the current trick
+	TricksWon [4]int // by player
+
+	// Inference holds what this player can infer about the other hands.
+	Inference Inference
+
+	// View is the public view of the game sent with the current request.
+	View game.View
+
+	pacing    Pacing
+	interrupt <-chan struct{}
+}
+
+// BotBase implements Paced, Interruptible and ViewReceiver.
+var _ Paced = &BotBase{}
+var _ Interruptible = &BotBase{}
+var _ ViewReceiver = &BotBase{}
+
+func (b *BotBase) SetPacing(pc Pacing) { b.pacing = pc }
+
+func (b *BotBase) SetInterrupt(interrupt <-chan struct{}) { b.interrupt = interrupt }
+
+func (b *BotBase) SetView(v game.View) { b.View = v }
+
+// Pause waits before a decision, as set by the pacing. It returns early if
+// the decision is interrupted.
+func (b *BotBase) Pause() {
+	if b.pacing == nil {
+		return
+	}
+	t := time.NewTimer(b.pacing.Delay(PauseBotDecision))
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-b.interrupt:
+	}
+}
+
+// Interrupted says whether the decision being made has been abandoned, so
+// that a bot can cut a long search short.
+func (b *BotBase) Interrupted() bool {
+	select {
+	case <-b.interrupt:
+		return true
+	default:
+		return false
+	}
+}
+
+func (b *BotBase) NotifyPlayerNum(n int) {
+	*b = BotBase{
+		Seat:       n,
+		HighBidder: -1,
+		Contractor: -1,
+		Leader:     -1,
+		pacing:     b.pacing,
+		interrupt:  b.interrupt,
+	}
+	b.Inference.NotifyPlayerNum(n)
+}
+
+func (b *BotBase) NotifyHand(hand *c.List[card.Card]) {
+	// The hand the contractor is sent after discarding tells us which cards
+	// were discarded
+	if b.Kitty != nil && b.Discards == nil && b.Hand != nil && hand.Size() < b.Hand.Size() {
+		b.Discards = b.Hand.Filter(func(_ int, cd card.Card) bool { return !hand.Contains(cd) })
+		b.Inference.NotifyDiscards(b.Discards)
+	}
+	b.Hand = hand
+	b.Inference.NotifyHand(hand)
+}
+
+func (b *BotBase) NotifyBid(player int, bid game.Bid) {
+	b.Bids[player] = append(b.Bids[player], bid)
+	if (bid != game.Pass{}) {
+		b.HighBid = bid
+		b.HighBidder = player
+	}
+	b.Inference.NotifyBid(player, bid)
+}
+
+func (b *BotBase) NotifyBidWinner(player int, bid game.Bid) {
+	b.Contract = bid
+	b.Contractor = player
+	b.Leader = player
+	b.Inference.NotifyBidWinner(player, bid)
+}
+
+func (b *BotBase) NotifyKitty(kitty *c.List[card.Card]) { b.Kitty = kitty }
+func (b *BotBase) NotifyKittyTaken(player int)          {}
+
+func (b *BotBase) NotifyPlay(player int, cd card.Card) {
+	b.Trick = append(b.Trick, game.PlayInfo{Player: player, Card: cd})
+	b.Inference.NotifyPlay(player, cd)
+}
+
+func (b *BotBase) NotifyTrickWinner(player int) {
+	b.Tricks = append(b.Tricks, b.Trick)
+	b.Trick = nil
+	b.Leader = player
+	b.TricksWon[player]++
+	b.Inference.NotifyTrickWinner(player)
+}
+
+func (b *BotBase) NotifyHandResult(res game.HandResult) {}
+
+// LastBid returns the last bid (other than a pass) made by the player, or nil
+// if they haven't bid.
+func (b *BotBase) LastBid(player int) game.Bid {
+	for i := len(b.Bids[player]) - 1; i >= 0; i-- {
+		if bid := b.Bids[player][i]; (bid != game.Pass{}) {
+			return bid
+		}
+	}
+	return nil
+}
+
+// TeamTricks returns the number of tricks won by the player's team.
+func (b *BotBase) TeamTricks(player int) int {
+	return b.TricksWon[player] + b.TricksWon[partner(player)]
+}
@@ -0,0 +1,55 @@
+package player
+
+import (
+	"testing"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBotBase(t *testing.T) {
+	b := &BotBase{}
+	b.NotifyPlayerNum(1)
+	h := hand(
+		card.Card{5, card.Spades}, card.Card{6, card.Spades}, card.Card{7, card.Spades},
+		card.Card{8, card.Spades}, card.Card{9, card.Spades}, card.Card{10, card.Spades},
+		card.Card{5, card.Clubs}, card.Card{6, card.Clubs}, card.Card{7, card.Clubs},
+		card.Card{8, card.Clubs},
+	)
+	b.NotifyHand(h)
+	b.NotifyBid(0, game.Pass{})
+	b.NotifyBid(1, game.SuitBid{6, card.Spades})
+	b.NotifyBid(2, game.Pass{})
+	b.NotifyBid(3, game.Pass{})
+	b.NotifyBid(0, game.Pass{})
+	assert.Equal(t, game.SuitBid{6, card.Spades}, b.HighBid)
+	assert.Equal(t, 1, b.HighBidder)
+	assert.Nil(t, b.LastBid(0))
+
+	// Pick up the kitty, then discard it
+	kitty := hand(card.Card{4, card.Hearts}, card.Card{5, card.Hearts}, card.Card{6, card.Hearts})
+	b.NotifyBidWinner(1, game.SuitBid{6, card.Spades})
+	b.NotifyKitty(kitty)
+	b.NotifyHand(hand(append(*h.Copy(), *kitty...)...))
+	b.NotifyHand(h)
+	assert.Equal(t, kitty, b.Discards)
+	assert.True(t, b.Inference.Seen(card.Card{5, card.Hearts}))
+
+	b.NotifyPlay(1, card.Card{10, card.Spades})
+	b.NotifyPlay(2, card.Card{card.Jack, card.Spades})
+	b.NotifyPlay(3, card.Card{card.Queen, card.Hearts})
+	b.NotifyPlay(0, card.Card{4, card.Diamonds})
+	b.NotifyTrickWinner(2)
+	assert.Len(t, b.Tricks, 1)
+	assert.Nil(t, b.Trick)
+	assert.Equal(t, 2, b.Leader)
+	assert.Equal(t, 1, b.TeamTricks(0))
+	assert.Equal(t, 0, b.TeamTricks(1))
+	assert.True(t, b.Inference.Void(3, card.Spades))
+
+	// A new hand resets everything
+	b.NotifyPlayerNum(1)
+	assert.Nil(t, b.Contract)
+	assert.Nil(t, b.Tricks)
+}
@@ -0,0 +1,283 @@
+package player
+
+import (
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/util"
+
+	c "github.com/barrettj12/collections"
+)
+
+// choosePlay picks a card for the HeuristicPlayer to play, and returns its
+// index in the hand. It uses standard card-play heuristics:
+//   - second hand low, third hand high, and don't overtake a winning partner;
+//   - the contractor draws trumps, and defenders lead their partner's suit;
+//   - in misère, the contractor plays the highest card which won't win, and
+//     the defenders duck underneath the contractor's card.
+func (p *HeuristicPlayer) choosePlay(valid *c.List[int]) int {
+	if len(p.Trick) == 0 {
+		return p.lead(valid)
+	}
+
+	lead := p.Trick[0].Card
+	winner := p.trickWinner()
+	beating := valid.Filter(func(_ int, i int) bool {
+		return p.beats(p.card(i), winner.Card, lead)
+	})
+	losing := valid.Filter(func(_ int, i int) bool {
+		return !p.beats(p.card(i), winner.Card, lead)
+	})
+
+	if _, ok := p.Contract.(game.MisereBid); ok {
+		if p.Seat == p.Contractor {
+			// Get rid of the highest card which won't win the trick
+			if losing.Size() > 0 {
+				return p.because("shed the highest card which won't win", p.highest(losing))
+			}
+			return p.because("can't avoid winning, so win high", p.highest(valid))
+		}
+		if winner.Player == p.Contractor && losing.Size() > 0 {
+			// Duck underneath the contractor's card
+			return p.because("duck under the contractor's card", p.highest(losing))
+		}
+		return p.because("play low, to keep the contractor winning", p.lowest(valid))
+	}
+
+	partnerWinning := winner.Player == partner(p.Seat)
+	switch len(p.Trick) {
+	case 1:
+		// Second hand low, unless covering a high card
+		if cover := p.weights().CoverRank; cover > 0 && rankValue(lead) >= cover && beating.Size() > 0 {
+			return p.because("cover a high card", p.lowest(beating))
+		}
+		return p.because("second hand plays low", p.lowest(valid))
+
+	case 2:
+		// Third hand high, unless partner has the trick won
+		if partnerWinning && p.isTop(winner.Card, lead) {
+			return p.because("partner is winning, play low", p.lowest(valid))
+		}
+		if beating.Size() == 0 {
+			return p.because("can't win the trick, play low", p.lowest(valid))
+		}
+		top := beating.Filter(func(_ int, i int) bool { return p.isTop(p.card(i), lead) })
+		if top.Size() > 0 {
+			return p.because("win with the cheapest sure winner", p.lowest(top))
+		}
+		return p.because("third hand plays high", p.highest(beating))
+
+	default:
+		// Last to play: win as cheaply as possible
+		if partnerWinning {
+			return p.because("partner is winning, play low", p.lowest(valid))
+		}
+		if beating.Size() == 0 {
+			return p.because("can't win the trick, play low", p.lowest(valid))
+		}
+		return p.because("win as cheaply as possible", p.lowest(beating))
+	}
+}
+
+// defaultPlay returns the card choosePlay would pick, without adding its rule
+// to the explanation.
+func (p *HeuristicPlayer) defaultPlay(valid *c.List[int]) int {
+	expl := p.expl
+	defer func() { p.expl = expl }()
+	return p.choosePlay(valid)
+}
+
+// lead picks a card to lead.
+func (p *HeuristicPlayer) lead(valid *c.List[int]) int {
+	if _, ok := p.Contract.(game.MisereBid); ok {
+		return p.because("lead low in misère", p.lowest(valid))
+	}
+
+	bid, suitContract := p.Contract.(game.SuitBid)
+	isTrump := func(i int) bool {
+		return suitContract && bid.Suit(p.card(i)) == bid.TrumpSuit
+	}
+	trumps := valid.Filter(func(_ int, i int) bool { return isTrump(i) })
+	side := valid.Filter(func(_ int, i int) bool { return !isTrump(i) })
+
+	// Contractor draws trumps
+	if p.Seat == p.Contractor && trumps.Size() > 0 && trumps.Size() >= p.weights().DrawTrumps && p.opponentsMayHoldTrumps() {
+		return p.because("draw trumps", p.highest(trumps))
+	}
+
+	// Cash a winner, unless an opponent may trump it
+	for _, i := range *side {
+		if cd := p.card(i); cd != card.JokerCard && p.isTopOfSuit(cd) && !p.mayBeRuffed(cd) {
+			return p.because("cash a winner", i)
+		}
+	}
+
+	// Defenders lead their partner's suit
+	if ps := p.partnerSuit(); p.Seat != p.Contractor && p.Seat != partner(p.Contractor) && ps != card.NoSuit {
+		inSuit := side.Filter(func(_ int, i int) bool { return p.Contract.Suit(p.card(i)) == ps })
+		if inSuit.Size() > 0 {
+			return p.because("lead partner's suit", p.lowest(inSuit))
+		}
+	}
+
+	if side.Size() == 0 {
+		return p.because("only trumps left", p.lowest(valid))
+	}
+	// Lead from the shortest side suit to set up ruffs, or the longest suit
+	// in no trumps (or if every suit is too long to ruff) to set up long
+	// cards
+	bySuit := map[card.Suit]*c.List[int]{}
+	for _, i := range *side {
+		s := p.Contract.Suit(p.card(i))
+		if bySuit[s] == nil {
+			bySuit[s] = c.NewList[int](0)
+		}
+		bySuit[s].Append(i)
+	}
+	var shortest, longest *c.List[int]
+	for _, s := range suits {
+		l := bySuit[s]
+		if l == nil {
+			continue
+		}
+		if shortest == nil || l.Size() < shortest.Size() {
+			shortest = l
+		}
+		if longest == nil || l.Size() > longest.Size() {
+			longest = l
+		}
+	}
+	if shortest == nil {
+		return p.because("lead low", p.lowest(side))
+	}
+	if suitContract && shortest.Size() <= p.weights().RuffLength {
+		return p.because("lead the shortest suit, to set up ruffs", p.lowest(shortest))
+	}
+	return p.because("lead the longest suit, to set up long cards", p.lowest(longest))
+}
+
+// because records the rule which chose a card, and returns the card's index.
+func (p *HeuristicPlayer) because(rule string, i int) int {
+	p.rule(rule)
+	return i
+}
+
+// card returns the card at index i in the hand.
+func (p *HeuristicPlayer) card(i int) card.Card {
+	return util.E(p.Hand.Get(i))
+}
+
+// trickWinner returns the play currently winning the trick.
+func (p *HeuristicPlayer) trickWinner() game.PlayInfo {
+	lead := p.Trick[0].Card
+	winner := p.Trick[0]
+	for _, pl := range p.Trick[1:] {
+		if p.beats(pl.Card, winner.Card, lead) {
+			winner = pl
+		}
+	}
+	return winner
+}
+
+// beats returns true if card a beats card b in a trick with the given lead.
+func (p *HeuristicPlayer) beats(a, b, lead card.Card) bool {
+	order := p.Contract.CardOrder(lead)
+	i, err := order.Find(a)
+	if err != nil {
+		return false
+	}
+	j, err := order.Find(b)
+	return err != nil || i < j
+}
+
+// isTop returns true if no unseen card beats the given card, in a trick with
+// the given lead.
+func (p *HeuristicPlayer) isTop(cd, lead card.Card) bool {
+	for _, higher := range *p.Contract.CardOrder(lead) {
+		if higher == cd {
+			return true
+		}
+		if !p.Inference.Seen(higher) {
+			return false
+		}
+	}
+	return false
+}
+
+// isTopOfSuit returns true if no unseen card beats the given card when it is
+// led, other than trumps.
+func (p *HeuristicPlayer) isTopOfSuit(cd card.Card) bool {
+	bid, suitContract := p.Contract.(game.SuitBid)
+	for _, higher := range *p.Contract.CardOrder(cd) {
+		if higher == cd {
+			return true
+		}
+		if suitContract && bid.Suit(higher) == bid.TrumpSuit {
+			continue
+		}
+		if !p.Inference.Seen(higher) {
+			return false
+		}
+	}
+	return false
+}
+
+// mayBeRuffed returns true if an opponent who has shown out of the card's suit
+// may hold a trump.
+func (p *HeuristicPlayer) mayBeRuffed(cd card.Card) bool {
+	bid, ok := p.Contract.(game.SuitBid)
+	if !ok || !p.opponentsMayHoldTrumps() {
+		return false
+	}
+	for _, opp := range []int{(p.Seat + 1) % 4, (p.Seat + 3) % 4} {
+		if p.Inference.Void(opp, bid.Suit(cd)) && !p.Inference.Void(opp, bid.TrumpSuit) {
+			return true
+		}
+	}
+	return false
+}
+
+// opponentsMayHoldTrumps returns true if there are unseen trumps, and an
+// opponent hasn't shown out of trumps.
+func (p *HeuristicPlayer) opponentsMayHoldTrumps() bool {
+	bid := p.Contract.(game.SuitBid)
+	if p.Inference.Void((p.Seat+1)%4, bid.TrumpSuit) && p.Inference.Void((p.Seat+3)%4, bid.TrumpSuit) {
+		return false
+	}
+	for _, cd := range *game.GetDeck() {
+		if bid.Suit(cd) == bid.TrumpSuit && !p.Inference.Seen(cd) {
+			return true
+		}
+	}
+	return false
+}
+
+// strength orders cards from weakest to strongest, with trumps above
+// everything else.
+func (p *HeuristicPlayer) strength(cd card.Card) int {
+	if bid, ok := p.Contract.(game.SuitBid); ok && bid.Suit(cd) == bid.TrumpSuit {
+		return 100 + trumpRank(cd, bid)
+	}
+	return rankValue(cd)
+}
+
+// lowest returns the index of the weakest of the given cards.
+func (p *HeuristicPlayer) lowest(indices *c.List[int]) int {
+	best := util.E(indices.Get(0))
+	for _, i := range *indices {
+		if p.strength(p.card(i)) < p.strength(p.card(best)) {
+			best = i
+		}
+	}
+	return best
+}
+
+// highest returns the index of the strongest of the given cards.
+func (p *HeuristicPlayer) highest(indices *c.List[int]) int {
+	best := util.E(indices.Get(0))
+	for _, i := range *indices {
+		if p.strength(p.card(i)) > p.strength(p.card(best)) {
+			best = i
+		}
+	}
+	return best
+}
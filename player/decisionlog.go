@@ -0,0 +1,292 @@
+package player
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+
+	c "github.com/barrettj12/collections"
+)
+
+// DecisionRecord describes one decision made by a player: what the player
+// could see at the time, the legal options, and what they chose. Cards and
+// bids are written as in the ExecPlayer protocol, e.g. "10H", "JK", "7S",
+// "8NT" or "pass".
+type DecisionRecord struct {
+	Game string `json:"game,omitempty"`
+	Seat int    `json:"seat"`
+	// Kind is the kind of decision: "bid", "discard", "play" or "jokersuit".
+	Kind string `json:"kind"`
+
+	// Information set
+	Hand       []string       `json:"hand"`
+	Kitty      []string       `json:"kitty,omitempty"` // if the player picked it up
+	Bids       []BidRecord    `json:"bids"`
+	Contract   string         `json:"contract,omitempty"`
+	Contractor *int           `json:"contractor,omitempty"`
+	Tricks     [][]PlayRecord `json:"tricks,omitempty"` // completed tricks
+	Trick      []PlayRecord   `json:"trick,omitempty"`  // the current trick
+
+	// Options are the legal choices. For a discard, they are the cards in
+	// the hand, of which three are chosen.
+	Options []string `json:"options"`
+	// Choice is the option chosen. For a discard, it is the three cards
+	// separated by spaces.
+	Choice string `json:"choice"`
+	// Millis is the time taken to decide, in milliseconds.
+	Millis int64 `json:"ms"`
+}
+
+// BidRecord is a bid in a DecisionRecord.
+type BidRecord struct {
+	Player int    `json:"player"`
+	Bid    string `json:"bid"`
+}
+
+// PlayRecord is a card played in a DecisionRecord.
+type PlayRecord struct {
+	Player int    `json:"player"`
+	Card   string `json:"card"`
+}
+
+// DecisionLogger wraps a Player, writing a DecisionRecord for each of its
+// decisions to W, one JSON object per line. It is used to collect games for
+// offline analysis.
+//
+// A decision which is taken back stays in the log, and is followed by the
+// decision which replaces it. A decision which is interrupted isn't logged.
+type DecisionLogger struct {
+	Player
+	W io.Writer
+	// Game is written in each record, to tell games apart.
+	Game string
+
+	state     BotBase
+	bids      []BidRecord // in the order they were made
+	err       error
+	interrupt <-chan struct{}
+}
+
+// DecisionLogger implements Player, Paced, Takebacker, Rewinder,
+// ClockWatcher, Interruptible and ViewReceiver, passing the optional
+// interfaces on to the wrapped player.
+var (
+	_ Player        = &DecisionLogger{}
+	_ Paced         = &DecisionLogger{}
+	_ Takebacker    = &DecisionLogger{}
+	_ Rewinder      = &DecisionLogger{}
+	_ ClockWatcher  = &DecisionLogger{}
+	_ Interruptible = &DecisionLogger{}
+	_ ViewReceiver  = &DecisionLogger{}
+)
+
+// Err returns the first error writing a record, if any.
+func (l *DecisionLogger) Err() error { return l.err }
+
+func (l *DecisionLogger) NotifyPlayerNum(n int) {
+	l.state.NotifyPlayerNum(n)
+	l.bids = nil
+	l.Player.NotifyPlayerNum(n)
+}
+
+func (l *DecisionLogger) NotifyHand(hand *c.List[card.Card]) {
+	l.state.NotifyHand(hand)
+	l.Player.NotifyHand(hand)
+}
+
+func (l *DecisionLogger) NotifyBid(player int, bid game.Bid) {
+	l.state.NotifyBid(player, bid)
+	l.bids = append(l.bids, BidRecord{player, encodeBid(bid)})
+	l.Player.NotifyBid(player, bid)
+}
+
+func (l *DecisionLogger) NotifyBidWinner(player int, bid game.Bid) {
+	l.state.NotifyBidWinner(player, bid)
+	l.Player.NotifyBidWinner(player, bid)
+}
+
+func (l *DecisionLogger) NotifyKitty(kitty *c.List[card.Card]) {
+	l.state.NotifyKitty(kitty)
+	l.Player.NotifyKitty(kitty)
+}
+
+func (l *DecisionLogger) NotifyPlay(player int, cd card.Card) {
+	l.state.NotifyPlay(player, cd)
+	l.Player.NotifyPlay(player, cd)
+}
+
+func (l *DecisionLogger) NotifyTrickWinner(player int) {
+	l.state.NotifyTrickWinner(player)
+	l.Player.NotifyTrickWinner(player)
+}
+
+func (l *DecisionLogger) Bid() game.Bid {
+	options := []string{encodeBid(game.Pass{})}
+	for _, b := range game.AllBids() {
+		if l.state.HighBid == nil || b.Value() > l.state.HighBid.Value() {
+			options = append(options, encodeBid(b))
+		}
+	}
+	start := time.Now()
+	bid := l.Player.Bid()
+	if l.interrupted() {
+		return bid
+	}
+	l.write("bid", options, encodeBid(bid), start)
+	return bid
+}
+
+func (l *DecisionLogger) Discard() *c.List[card.Card] {
+	options := encodeCardList(l.state.Hand)
+	start := time.Now()
+	cards := l.Player.Discard()
+	if l.interrupted() {
+		return cards
+	}
+	l.write("discard", options, strings.Join(encodeCardList(cards), " "), start)
+	return cards
+}
+
+func (l *DecisionLogger) Play(trick *c.List[game.PlayInfo], validPlays *c.List[int]) int {
+	var options []string
+	for _, i := range *validPlays {
+		cd, _ := l.state.Hand.Get(i)
+		options = append(options, encodeCard(cd))
+	}
+	start := time.Now()
+	i := l.Player.Play(trick, validPlays)
+	if l.interrupted() {
+		return i
+	}
+	cd, _ := l.state.Hand.Get(i)
+	l.write("play", options, encodeCard(cd), start)
+	return i
+}
+
+func (l *DecisionLogger) JokerSuit() card.Suit {
+	options := []string{"S", "C", "D", "H"}
+	start := time.Now()
+	suit := l.Player.JokerSuit()
+	if l.interrupted() {
+		return suit
+	}
+	l.write("jokersuit", options, suitLetters[suit], start)
+	return suit
+}
+
+// interrupted says whether the decision just made was interrupted, in which
+// case its answer is discarded.
+func (l *DecisionLogger) interrupted() bool {
+	select {
+	case <-l.interrupt:
+		return true
+	default:
+		return false
+	}
+}
+
+// write writes a record of a decision.
+func (l *DecisionLogger) write(kind string, options []string, choice string, start time.Time) {
+	s := &l.state
+	rec := DecisionRecord{
+		Game:    l.Game,
+		Seat:    s.Seat,
+		Kind:    kind,
+		Hand:    encodeCardList(s.Hand),
+		Bids:    append([]BidRecord{}, l.bids...),
+		Options: options,
+		Choice:  choice,
+		Millis:  time.Since(start).Milliseconds(),
+	}
+	if s.Kitty != nil {
+		rec.Kitty = encodeCardList(s.Kitty)
+	}
+	if s.Contract != nil {
+		rec.Contract = encodeBid(s.Contract)
+		contractor := s.Contractor
+		rec.Contractor = &contractor
+	}
+	for _, t := range s.Tricks {
+		rec.Tricks = append(rec.Tricks, encodePlays(t))
+	}
+	rec.Trick = encodePlays(s.Trick)
+
+	if err := json.NewEncoder(l.W).Encode(rec); err != nil && l.err == nil {
+		l.err = err
+	}
+}
+
+func encodeCardList(cards *c.List[card.Card]) []string {
+	s := []string{}
+	if cards != nil {
+		for _, cd := range *cards {
+			s = append(s, encodeCard(cd))
+		}
+	}
+	return s
+}
+
+func encodePlays(plays []game.PlayInfo) []PlayRecord {
+	var recs []PlayRecord
+	for _, p := range plays {
+		recs = append(recs, PlayRecord{p.Player, encodeCard(p.Card)})
+	}
+	return recs
+}
+
+func (l *DecisionLogger) SetPacing(pc Pacing) {
+	if p, ok := l.Player.(Paced); ok {
+		p.SetPacing(pc)
+	}
+}
+
+func (l *DecisionLogger) WantsTakeback() bool {
+	t, ok := l.Player.(Takebacker)
+	return ok && t.WantsTakeback()
+}
+
+func (l *DecisionLogger) AllowTakeback(player int) bool {
+	t, ok := l.Player.(Takebacker)
+	return !ok || t.AllowTakeback(player)
+}
+
+func (l *DecisionLogger) NotifyTakebackRefused() {
+	if t, ok := l.Player.(Takebacker); ok {
+		t.NotifyTakebackRefused()
+	}
+}
+
+func (l *DecisionLogger) NotifyTakeback(player int) {
+	if r, ok := l.Player.(Rewinder); ok {
+		r.NotifyTakeback(player)
+	}
+}
+
+func (l *DecisionLogger) NotifyReplayDone() {
+	if r, ok := l.Player.(Rewinder); ok {
+		r.NotifyReplayDone()
+	}
+}
+
+func (l *DecisionLogger) NotifyClock(remaining [4]time.Duration, running int) {
+	if w, ok := l.Player.(ClockWatcher); ok {
+		w.NotifyClock(remaining, running)
+	}
+}
+
+func (l *DecisionLogger) SetView(v game.View) {
+	if vr, ok := l.Player.(ViewReceiver); ok {
+		vr.SetView(v)
+	}
+}
+
+func (l *DecisionLogger) SetInterrupt(interrupt <-chan struct{}) {
+	l.interrupt = interrupt
+	if i, ok := l.Player.(Interruptible); ok {
+		i.SetInterrupt(interrupt)
+	}
+}
@@ -0,0 +1,68 @@
+package player
+
+import (
+	"bufio"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/util"
+	"github.com/stretchr/testify/assert"
+
+	c "github.com/barrettj12/collections"
+)
+
+func TestDecisionLogger(t *testing.T) {
+	var out strings.Builder
+	l := &DecisionLogger{Player: &HeuristicPlayer{}, W: &out, Game: "test"}
+
+	h := hand(
+		card.Card{4, card.Hearts}, card.Card{5, card.Hearts}, card.Card{8, card.Spades}, card.Card{9, card.Spades},
+		card.Card{10, card.Spades}, card.Card{5, card.Clubs}, card.Card{6, card.Clubs}, card.Card{7, card.Clubs},
+		card.Card{4, card.Diamonds}, card.Card{5, card.Diamonds},
+	)
+	l.NotifyPlayerNum(1)
+	l.NotifyHand(h)
+	l.NotifyBid(0, game.SuitBid{7, card.Hearts})
+	bid := l.Bid()
+	l.NotifyBid(1, bid)
+	l.NotifyBidWinner(0, game.SuitBid{7, card.Hearts})
+	l.NotifyPlay(0, card.JokerCard)
+	valid := c.AsList([]int{0, 1})
+	i := l.Play(c.AsList([]game.PlayInfo{{0, card.JokerCard}}), valid)
+	assert.Contains(t, *valid, i)
+	assert.NoError(t, l.Err())
+
+	var recs []DecisionRecord
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		var rec DecisionRecord
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &rec))
+		recs = append(recs, rec)
+	}
+	if !assert.Len(t, recs, 2) {
+		return
+	}
+
+	bidRec := recs[0]
+	assert.Equal(t, "test", bidRec.Game)
+	assert.Equal(t, 1, bidRec.Seat)
+	assert.Equal(t, "bid", bidRec.Kind)
+	assert.Equal(t, []string{"4H", "5H", "8S", "9S", "10S", "5C", "6C", "7C", "4D", "5D"}, bidRec.Hand)
+	assert.Equal(t, []BidRecord{{0, "7H"}}, bidRec.Bids)
+	assert.Equal(t, "pass", bidRec.Options[0])
+	assert.NotContains(t, bidRec.Options, "7H")
+	assert.Contains(t, bidRec.Options, "7NT")
+	assert.Equal(t, encodeBid(bid), bidRec.Choice)
+	assert.Nil(t, bidRec.Contractor)
+
+	playRec := recs[1]
+	assert.Equal(t, "play", playRec.Kind)
+	assert.Equal(t, "7H", playRec.Contract)
+	assert.Equal(t, 0, *playRec.Contractor)
+	assert.Equal(t, []PlayRecord{{0, "JK"}}, playRec.Trick)
+	assert.Equal(t, []string{"4H", "5H"}, playRec.Options)
+	assert.Equal(t, encodeCard(util.E(h.Get(i))), playRec.Choice)
+}
@@ -0,0 +1,174 @@
+package player
+
+import (
+	"sort"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/util"
+
+	c "github.com/barrettj12/collections"
+)
+
+// PlanDiscard picks three cards for the contractor to discard, from their
+// hand after picking up the kitty.
+//   - In a suit contract, it keeps trumps and tries to void side suits, so
+//     that they can be trumped. Suits headed by an ace are kept.
+//   - In no trumps, it keeps the Joker, stoppers and long suits headed by
+//     the ace.
+//   - In misère, it sheds the Joker and the cards most likely to be forced
+//     to win a trick.
+func PlanDiscard(hand *c.List[card.Card], bid game.Bid) *c.List[card.Card] {
+	switch b := bid.(type) {
+	case game.MisereBid:
+		return discardMisere(hand)
+	case game.SuitBid:
+		return discardSuit(hand, b)
+	default:
+		return discardNoTrumps(hand)
+	}
+}
+
+// discardSuit voids the shortest side suits it can, then discards the
+// lowest side cards.
+func discardSuit(hand *c.List[card.Card], bid game.SuitBid) *c.List[card.Card] {
+	discards := c.NewList[card.Card](3)
+	bySuit := splitSuits(hand, bid)
+
+	var side []card.Suit
+	for _, s := range suits {
+		if s != bid.TrumpSuit && len(bySuit[s]) > 0 && !containsRank(bySuit[s], card.Ace) {
+			side = append(side, s)
+		}
+	}
+	sort.SliceStable(side, func(i, j int) bool {
+		return len(bySuit[side[i]]) < len(bySuit[side[j]])
+	})
+	for _, s := range side {
+		if len(bySuit[s]) <= 3-discards.Size() {
+			discards.Append(bySuit[s]...)
+		}
+	}
+
+	// Fill up with the lowest remaining cards, trumps last
+	keep := func(cd card.Card) int {
+		if bid.Suit(cd) == bid.TrumpSuit {
+			return 100 + trumpRank(cd, bid)
+		}
+		v := rankValue(cd)
+		if cd.Rank == card.King && len(bySuit[cd.Suit]) >= 2 {
+			// Guarded king
+			v += 10
+		}
+		return v
+	}
+	fill(discards, hand, keep)
+	return discards
+}
+
+// discardNoTrumps keeps the Joker, stoppers and long suits, and discards the
+// lowest other cards.
+func discardNoTrumps(hand *c.List[card.Card]) *c.List[card.Card] {
+	bySuit := splitSuits(hand, game.NoTrumpsBid{})
+	keep := func(cd card.Card) int {
+		if cd == card.JokerCard {
+			return 100
+		}
+		cards := bySuit[cd.Suit]
+		v := rankValue(cd)
+		switch {
+		case isStopper(cd, cards):
+			return 50 + v
+		case len(cards) >= 5 && containsRank(cards, card.Ace):
+			// Long suit which can be run
+			return 30 + v
+		case guardsStopper(cd, cards):
+			return 20 + v
+		}
+		return v
+	}
+
+	discards := c.NewList[card.Card](3)
+	fill(discards, hand, keep)
+	return discards
+}
+
+// discardMisere repeatedly discards the card which most reduces the risk of
+// winning a trick, preferring higher cards.
+func discardMisere(hand *c.List[card.Card]) *c.List[card.Card] {
+	discards := c.NewList[card.Card](3)
+	rest := hand.Copy()
+	for discards.Size() < 3 {
+		best, bestRisk := -1, 0
+		for i, cd := range *rest {
+			without := rest.Filter(func(j int, _ card.Card) bool { return j != i })
+			risk := MisereRisk(without, false)
+			if best == -1 || risk < bestRisk ||
+				(risk == bestRisk && rankValue(cd) > rankValue((*rest)[best])) {
+				best, bestRisk = i, risk
+			}
+		}
+		discards.Append(util.E(rest.Remove(best)))
+	}
+	return discards
+}
+
+// fill adds the cards with the lowest keep value to discards, until there
+// are three.
+func fill(discards, hand *c.List[card.Card], keep func(card.Card) int) {
+	rest := hand.Filter(func(_ int, cd card.Card) bool {
+		return !discards.Contains(cd)
+	})
+	sort.SliceStable(*rest, func(i, j int) bool {
+		return keep((*rest)[i]) < keep((*rest)[j])
+	})
+	for _, cd := range *rest {
+		if discards.Size() >= 3 {
+			return
+		}
+		discards.Append(cd)
+	}
+}
+
+// trumpRank orders the trumps, with the Joker highest.
+func trumpRank(cd card.Card, bid game.SuitBid) int {
+	order := bid.CardOrder(cd)
+	return order.Size() - util.E(order.Find(cd))
+}
+
+// isStopper returns true if the card stops its suit in no trumps: an ace, a
+// king with one guard, or a queen with two.
+func isStopper(cd card.Card, cards []card.Card) bool {
+	switch cd.Rank {
+	case card.Ace:
+		return true
+	case card.King:
+		return len(cards) >= 2
+	case card.Queen:
+		return len(cards) >= 3 && !containsRank(cards, card.Ace) && !containsRank(cards, card.King)
+	}
+	return false
+}
+
+// guardsStopper returns true if the card is needed to guard a king or queen
+// in its suit.
+func guardsStopper(cd card.Card, cards []card.Card) bool {
+	if containsRank(cards, card.Ace) {
+		return false
+	}
+	guards := 0
+	switch {
+	case containsRank(cards, card.King):
+		guards = 1
+	case containsRank(cards, card.Queen):
+		guards = 2
+	}
+	// The lowest cards in the suit are the guards
+	lower := 0
+	for _, other := range cards {
+		if rankValue(other) < rankValue(cd) {
+			lower++
+		}
+	}
+	return cd.Rank < card.Queen && lower < guards
+}
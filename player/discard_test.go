@@ -0,0 +1,47 @@
+package player
+
+import (
+	"testing"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanDiscard(t *testing.T) {
+	// Voids clubs and keeps the trumps and A♠
+	h := hand(
+		card.JokerCard, card.Card{card.Jack, card.Hearts}, card.Card{card.Ace, card.Hearts},
+		card.Card{9, card.Hearts}, card.Card{7, card.Hearts}, card.Card{5, card.Hearts},
+		card.Card{card.Ace, card.Spades}, card.Card{5, card.Spades},
+		card.Card{6, card.Clubs}, card.Card{card.Queen, card.Clubs},
+		card.Card{8, card.Diamonds}, card.Card{9, card.Diamonds}, card.Card{card.King, card.Diamonds},
+	)
+	assert.ElementsMatch(t, []card.Card{
+		{6, card.Clubs}, {card.Queen, card.Clubs}, {5, card.Spades},
+	}, *PlanDiscard(h, game.SuitBid{7, card.Hearts}))
+
+	// Keeps stoppers (A♥, K♦ and its guard) and the long spades
+	h = hand(
+		card.JokerCard, card.Card{card.Ace, card.Hearts}, card.Card{5, card.Hearts},
+		card.Card{card.Ace, card.Spades}, card.Card{card.King, card.Spades}, card.Card{9, card.Spades},
+		card.Card{7, card.Spades}, card.Card{6, card.Spades},
+		card.Card{6, card.Clubs}, card.Card{card.Jack, card.Clubs},
+		card.Card{8, card.Diamonds}, card.Card{card.King, card.Diamonds}, card.Card{card.Queen, card.Hearts},
+	)
+	assert.ElementsMatch(t, []card.Card{
+		{6, card.Clubs}, {card.Jack, card.Clubs}, {5, card.Hearts},
+	}, *PlanDiscard(h, game.NoTrumpsBid{Tricks: 7}))
+
+	// Sheds the Joker and the high cards
+	h = hand(
+		card.JokerCard, card.Card{4, card.Hearts}, card.Card{card.King, card.Hearts},
+		card.Card{5, card.Spades}, card.Card{6, card.Spades}, card.Card{8, card.Spades},
+		card.Card{5, card.Clubs}, card.Card{7, card.Clubs}, card.Card{card.Ace, card.Clubs},
+		card.Card{4, card.Diamonds}, card.Card{5, card.Diamonds}, card.Card{6, card.Diamonds},
+		card.Card{7, card.Diamonds},
+	)
+	assert.ElementsMatch(t, []card.Card{
+		card.JokerCard, {card.King, card.Hearts}, {card.Ace, card.Clubs},
+	}, *PlanDiscard(h, game.MisereBid{}))
+}
@@ -0,0 +1,480 @@
+package player
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+
+	c "github.com/barrettj12/collections"
+)
+
+// ExecPlayer is a player run as a separate program, so that bots can be
+// written in any language. The program reads events and requests from its
+// standard input, and writes its replies to its standard output, one line at
+// a time.
+//
+// The program is started by the first event (or by Start). The controller
+// first sends the protocol version, and the program replies with its name:
+//
+//	500 1                  ready NAME
+//
+// Events are sent as they happen, and need no reply:
+//
+//	seat N                 the program's seat (0-3), sent at the start of
+//	                       each hand, and when a hand is replayed after a
+//	                       takeback
+//	hand CARD...           the cards in the program's hand
+//	bid PLAYER BID         a player bid (or passed)
+//	contract PLAYER BID    bidding is finished
+//	kitty CARD...          the kitty, when the program is the contractor
+//	kittytaken PLAYER      the contractor picked up the kitty
+//	play PLAYER CARD       a player played a card
+//	trick PLAYER           a player won the trick
+//	result RESULT          the hand is finished: "won TRICKS", "lost TRICKS",
+//	                       "redeal" or "forfeit PLAYER"
+//	quit                   the game is over, and the program should exit
+//
+// Requests carry an ID, which must be repeated in the reply:
+//
+//	request ID bid                reply ID BID
+//	request ID discard            reply ID CARD CARD CARD
+//	request ID play CARD...       reply ID CARD (one of the valid plays given)
+//	request ID jokersuit          reply ID SUIT
+//
+// Cards are written as a rank (4-10, J, Q, K, A) then a suit (S, C, D, H),
+// e.g. "10H" or "JS", and the Joker is "JK". Bids are "pass", a number of
+// tricks then a suit or "NT" (e.g. "7S" or "8NT"), "misere" or "openmisere".
+//
+// The program can write "info TEXT" lines at any time, which are logged and
+// otherwise ignored. Replies which are late (after a timeout) are ignored.
+type ExecPlayer struct {
+	// Path is the program to run, and Args are its arguments.
+	Path string
+	Args []string
+	// Timeout limits the time the program can take to reply to each request
+	// (and to the handshake). If zero, DefaultExecTimeout is used.
+	Timeout time.Duration
+	// Log, if set, receives a copy of all the lines sent to ("> ") and
+	// received from ("< ") the program.
+	Log io.Writer
+	// Stderr, if set, receives the program's standard error.
+	Stderr io.Writer
+
+	// Name is the name the program gave in the handshake.
+	Name string
+
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	lines   chan string
+	closing chan struct{}
+	exited  chan struct{}
+	waitErr error
+
+	hand   *c.List[card.Card]
+	nextID int
+}
+
+// DefaultExecTimeout is the time an ExecPlayer waits for each reply by default.
+const DefaultExecTimeout = 10 * time.Second
+
+// ExecPlayer implements PlayerV2.
+var _ PlayerV2 = &ExecPlayer{}
+
+// Start starts the program, and waits for it to complete the handshake. If
+// it fails, the program is stopped, and can be started again.
+func (p *ExecPlayer) Start(ctx context.Context) error {
+	cmd := exec.Command(p.Path, p.Args...)
+	cmd.Stderr = p.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting bot: %w", err)
+	}
+	p.cmd = cmd
+	p.stdin = stdin
+	p.lines = make(chan string)
+	p.closing = make(chan struct{})
+	p.exited = make(chan struct{})
+
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			select {
+			case p.lines <- scanner.Text():
+			case <-p.closing:
+				// Nothing is listening any more
+			}
+		}
+		close(p.lines)
+		p.waitErr = cmd.Wait()
+		close(p.exited)
+	}()
+
+	err = p.send("500 1")
+	var line string
+	if err == nil {
+		line, err = p.receive(ctx, func(line string) bool { return strings.HasPrefix(line, "ready") })
+	}
+	if err != nil {
+		p.stop()
+		return fmt.Errorf("handshake: %w", err)
+	}
+	p.Name = strings.TrimSpace(strings.TrimPrefix(line, "ready"))
+	return nil
+}
+
+// Close tells the program to quit, and kills it if it doesn't exit promptly.
+// It does nothing if the program isn't running.
+func (p *ExecPlayer) Close() error {
+	if p.cmd == nil {
+		return nil
+	}
+	_ = p.send("quit")
+	p.stdin.Close()
+	close(p.closing)
+	select {
+	case <-p.exited:
+	case <-time.After(time.Second):
+		p.kill()
+	}
+	p.cmd = nil
+	return nil
+}
+
+// stop kills the program without waiting for it to quit.
+func (p *ExecPlayer) stop() {
+	p.stdin.Close()
+	close(p.closing)
+	p.kill()
+	p.cmd = nil
+}
+
+func (p *ExecPlayer) kill() {
+	_ = p.cmd.Process.Kill()
+	<-p.exited
+}
+
+func (p *ExecPlayer) timeout() time.Duration {
+	if p.Timeout == 0 {
+		return DefaultExecTimeout
+	}
+	return p.Timeout
+}
+
+// send writes a line to the program.
+func (p *ExecPlayer) send(line string) error {
+	if p.Log != nil {
+		fmt.Fprintf(p.Log, "> %s\n", line)
+	}
+	if _, err := io.WriteString(p.stdin, line+"\n"); err != nil {
+		return p.crashed(err)
+	}
+	return nil
+}
+
+// receive returns the next line from the program which is wanted. Other lines
+// (info, and late replies) are skipped.
+func (p *ExecPlayer) receive(ctx context.Context, want func(line string) bool) (string, error) {
+	timer := time.NewTimer(p.timeout())
+	defer timer.Stop()
+	for {
+		select {
+		case line, ok := <-p.lines:
+			if !ok {
+				return "", p.crashed(nil)
+			}
+			if p.Log != nil {
+				fmt.Fprintf(p.Log, "< %s\n", line)
+			}
+			if want(line) {
+				return line, nil
+			}
+		case <-timer.C:
+			return "", fmt.Errorf("bot didn't reply within %v", p.timeout())
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// crashed returns the error for a program which has stopped responding.
+func (p *ExecPlayer) crashed(err error) error {
+	select {
+	case <-p.exited:
+		if p.waitErr != nil {
+			return fmt.Errorf("bot exited: %w", p.waitErr)
+		}
+		return fmt.Errorf("bot exited")
+	case <-time.After(time.Second):
+		return fmt.Errorf("bot stopped responding: %v", err)
+	}
+}
+
+func (p *ExecPlayer) Notify(ctx context.Context, e Event) error {
+	if p.cmd == nil {
+		if err := p.Start(ctx); err != nil {
+			return err
+		}
+	}
+
+	var line string
+	switch e := e.(type) {
+	case PlayerNumEvent:
+		line = fmt.Sprintf("seat %d", e.Player)
+	case HandEvent:
+		p.hand = e.Hand
+		line = "hand " + encodeCards(e.Hand)
+	case BidEvent:
+		line = fmt.Sprintf("bid %d %s", e.Player, encodeBid(e.Bid))
+	case BidWinnerEvent:
+		line = fmt.Sprintf("contract %d %s", e.Player, encodeBid(e.Bid))
+	case KittyEvent:
+		line = "kitty " + encodeCards(e.Kitty)
+	case KittyTakenEvent:
+		line = fmt.Sprintf("kittytaken %d", e.Player)
+	case PlayEvent:
+		line = fmt.Sprintf("play %d %s", e.Player, encodeCard(e.Card))
+	case TrickWinnerEvent:
+		line = fmt.Sprintf("trick %d", e.Player)
+	case HandResultEvent:
+		line = "result " + encodeResult(e.Result)
+	default:
+		// Not part of the protocol
+		return nil
+	}
+	return p.send(line)
+}
+
+func (p *ExecPlayer) Decide(ctx context.Context, r Request) (Response, error) {
+	var req string
+	switch r := r.(type) {
+	case BidRequest:
+		req = "bid"
+	case DiscardRequest:
+		req = "discard"
+	case PlayRequest:
+		valid := c.NewList[card.Card](r.ValidPlays.Size())
+		for _, i := range *r.ValidPlays {
+			valid.Append(p.card(i))
+		}
+		req = "play " + encodeCards(valid)
+	case JokerSuitRequest:
+		req = "jokersuit"
+	case AllowTakebackRequest:
+		// Not part of the protocol
+		return AllowTakebackResponse{true}, nil
+	default:
+		return nil, fmt.Errorf("unsupported request %T", r)
+	}
+
+	p.nextID++
+	prefix := fmt.Sprintf("reply %d", p.nextID)
+	if err := p.send(fmt.Sprintf("request %d %s", p.nextID, req)); err != nil {
+		return nil, err
+	}
+	line, err := p.receive(ctx, func(line string) bool {
+		return line == prefix || strings.HasPrefix(line, prefix+" ")
+	})
+	if err != nil {
+		return nil, err
+	}
+	reply := strings.Fields(strings.TrimPrefix(line, prefix))
+
+	resp, err := p.parseReply(r, reply)
+	if err != nil {
+		return nil, fmt.Errorf("bad reply %q: %w", line, err)
+	}
+	return resp, nil
+}
+
+// parseReply converts the bot's reply to a Response, checking that it is
+// valid.
+func (p *ExecPlayer) parseReply(r Request, reply []string) (Response, error) {
+	switch r := r.(type) {
+	case BidRequest:
+		if len(reply) != 1 {
+			return nil, fmt.Errorf("want one bid")
+		}
+		bid, err := parseBid(reply[0])
+		if err != nil {
+			return nil, err
+		}
+		if (bid != game.Pass{}) {
+			for _, b := range r.View.Bids {
+				if (b.Bid != game.Pass{}) && b.Bid.Value() >= bid.Value() {
+					return nil, fmt.Errorf("bid doesn't beat %s", b.Bid)
+				}
+			}
+		}
+		return BidResponse{bid}, nil
+
+	case DiscardRequest:
+		if len(reply) != 3 {
+			return nil, fmt.Errorf("want three cards")
+		}
+		cards := c.NewList[card.Card](3)
+		for _, s := range reply {
+			cd, err := parseCard(s)
+			if err != nil {
+				return nil, err
+			}
+			if !p.hand.Contains(cd) || cards.Contains(cd) {
+				return nil, fmt.Errorf("can't discard %s", s)
+			}
+			cards.Append(cd)
+		}
+		return DiscardResponse{cards}, nil
+
+	case PlayRequest:
+		if len(reply) != 1 {
+			return nil, fmt.Errorf("want one card")
+		}
+		cd, err := parseCard(reply[0])
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range *r.ValidPlays {
+			if p.card(i) == cd {
+				return PlayResponse{i}, nil
+			}
+		}
+		return nil, fmt.Errorf("%s isn't a valid play", reply[0])
+
+	case JokerSuitRequest:
+		if len(reply) != 1 {
+			return nil, fmt.Errorf("want one suit")
+		}
+		s, ok := parseSuit(reply[0])
+		if !ok {
+			return nil, fmt.Errorf("unknown suit %q", reply[0])
+		}
+		return JokerSuitResponse{s}, nil
+	}
+	return nil, fmt.Errorf("unsupported request %T", r)
+}
+
+func (p *ExecPlayer) card(i int) card.Card {
+	cd, _ := p.hand.Get(i)
+	return cd
+}
+
+var suitLetters = map[card.Suit]string{
+	card.Spades: "S", card.Clubs: "C", card.Diamonds: "D", card.Hearts: "H",
+}
+
+func parseSuit(s string) (card.Suit, bool) {
+	for suit, l := range suitLetters {
+		if l == s {
+			return suit, true
+		}
+	}
+	return card.NoSuit, false
+}
+
+func encodeCard(cd card.Card) string {
+	if cd == card.JokerCard {
+		return "JK"
+	}
+	return cd.Rank.String() + suitLetters[cd.Suit]
+}
+
+func parseCard(s string) (card.Card, error) {
+	if s == "JK" {
+		return card.JokerCard, nil
+	}
+	if len(s) < 2 {
+		return card.Card{}, fmt.Errorf("unknown card %q", s)
+	}
+	suit, ok := parseSuit(s[len(s)-1:])
+	if !ok {
+		return card.Card{}, fmt.Errorf("unknown card %q", s)
+	}
+	var rank card.Rank
+	switch r := s[:len(s)-1]; r {
+	case "A":
+		rank = card.Ace
+	case "J":
+		rank = card.Jack
+	case "Q":
+		rank = card.Queen
+	case "K":
+		rank = card.King
+	default:
+		n, err := strconv.Atoi(r)
+		if err != nil || n < 4 || n > 10 {
+			return card.Card{}, fmt.Errorf("unknown card %q", s)
+		}
+		rank = card.Rank(n)
+	}
+	return card.Card{Rank: rank, Suit: suit}, nil
+}
+
+func encodeCards(cards *c.List[card.Card]) string {
+	s := make([]string, 0, cards.Size())
+	for _, cd := range *cards {
+		s = append(s, encodeCard(cd))
+	}
+	return strings.Join(s, " ")
+}
+
+func encodeBid(bid game.Bid) string {
+	switch b := bid.(type) {
+	case game.Pass:
+		return "pass"
+	case game.SuitBid:
+		return fmt.Sprintf("%d%s", b.Tricks, suitLetters[b.TrumpSuit])
+	case game.NoTrumpsBid:
+		return fmt.Sprintf("%dNT", b.Tricks)
+	case game.MisereBid:
+		if b.Open {
+			return "openmisere"
+		}
+		return "misere"
+	}
+	panic(fmt.Sprintf("unknown bid %T", bid))
+}
+
+func parseBid(s string) (game.Bid, error) {
+	switch s {
+	case "pass":
+		return game.Pass{}, nil
+	case "misere":
+		return game.MisereBid{}, nil
+	case "openmisere":
+		return game.MisereBid{Open: true}, nil
+	}
+	for _, b := range game.AllBids() {
+		if encodeBid(b) == s {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown bid %q", s)
+}
+
+func encodeResult(res game.HandResult) string {
+	switch r := res.(type) {
+	case game.BidWon:
+		return fmt.Sprintf("won %d", r.Tricks)
+	case game.BidLost:
+		return fmt.Sprintf("lost %d", r.Tricks)
+	case game.Redeal:
+		return "redeal"
+	case game.Forfeit:
+		return fmt.Sprintf("forfeit %d", r.Player)
+	}
+	return "unknown"
+}
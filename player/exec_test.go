@@ -0,0 +1,163 @@
+package player
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/stretchr/testify/assert"
+
+	c "github.com/barrettj12/collections"
+)
+
+// TestExecHelperBot isn't a real test: it runs a simple bot when the test
+// binary is started by an ExecPlayer.
+func TestExecHelperBot(t *testing.T) {
+	mode := os.Getenv("EXEC_HELPER_BOT")
+	if mode == "" {
+		return
+	}
+	if mode == "early" {
+		// Exit before the handshake
+		os.Exit(4)
+	}
+
+	in := bufio.NewScanner(os.Stdin)
+	var hand []string
+	highBid := false
+	for in.Scan() {
+		f := strings.Fields(in.Text())
+		switch f[0] {
+		case "500":
+			fmt.Println("ready helper")
+		case "hand":
+			hand = f[1:]
+		case "bid":
+			highBid = highBid || f[2] != "pass"
+		case "quit":
+			os.Exit(0)
+		case "request":
+			fmt.Println("info thinking")
+			var reply string
+			switch mode {
+			case "crash":
+				os.Exit(3)
+			case "silent":
+				continue
+			}
+			switch f[2] {
+			case "bid":
+				reply = "pass"
+				if !highBid {
+					reply = "6S"
+				}
+			case "discard":
+				reply = strings.Join(hand[:3], " ")
+			case "play":
+				reply = f[3]
+			case "jokersuit":
+				reply = "H"
+			}
+			fmt.Printf("reply %s %s\n", f[1], reply)
+		}
+	}
+	os.Exit(0)
+}
+
+func helperBot(t *testing.T, mode string) *ExecPlayer {
+	t.Setenv("EXEC_HELPER_BOT", mode)
+	return &ExecPlayer{
+		Path:    os.Args[0],
+		Args:    []string{"-test.run=TestExecHelperBot"},
+		Timeout: 5 * time.Second,
+	}
+}
+
+func TestExecPlayer(t *testing.T) {
+	p := helperBot(t, "play")
+	var log strings.Builder
+	p.Log = &log
+	ctx := context.Background()
+	defer p.Close()
+
+	assert.NoError(t, p.Notify(ctx, PlayerNumEvent{0}))
+	assert.Equal(t, "helper", p.Name)
+
+	h := hand(card.JokerCard, card.Card{10, card.Hearts}, card.Card{card.Jack, card.Spades}, card.Card{4, card.Diamonds})
+	assert.NoError(t, p.Notify(ctx, HandEvent{h}))
+	resp, err := p.Decide(ctx, BidRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, BidResponse{game.SuitBid{6, card.Spades}}, resp)
+
+	assert.NoError(t, p.Notify(ctx, BidEvent{0, game.SuitBid{6, card.Spades}}))
+	resp, err = p.Decide(ctx, BidRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, BidResponse{game.Pass{}}, resp)
+
+	resp, err = p.Decide(ctx, DiscardRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, DiscardResponse{hand(card.JokerCard, card.Card{10, card.Hearts}, card.Card{card.Jack, card.Spades})}, resp)
+
+	resp, err = p.Decide(ctx, PlayRequest{ValidPlays: c.AsList([]int{1, 3})})
+	assert.NoError(t, err)
+	assert.Equal(t, PlayResponse{1}, resp)
+
+	resp, err = p.Decide(ctx, JokerSuitRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, JokerSuitResponse{card.Hearts}, resp)
+
+	assert.Contains(t, log.String(), "> 500 1\n< ready helper\n> seat 0\n> hand JK 10H JS 4D\n> request 1 bid\n< info thinking\n< reply 1 6S\n")
+	assert.Contains(t, log.String(), "> request 4 play 10H 4D\n< info thinking\n< reply 4 10H\n")
+}
+
+func TestExecPlayerCrash(t *testing.T) {
+	p := helperBot(t, "crash")
+	ctx := context.Background()
+	defer p.Close()
+
+	assert.NoError(t, p.Notify(ctx, PlayerNumEvent{0}))
+	_, err := p.Decide(ctx, BidRequest{})
+	assert.ErrorContains(t, err, "bot exited: exit status 3")
+}
+
+func TestExecPlayerTimeout(t *testing.T) {
+	p := helperBot(t, "silent")
+	p.Timeout = 100 * time.Millisecond
+	ctx := context.Background()
+	defer p.Close()
+
+	assert.NoError(t, p.Notify(ctx, PlayerNumEvent{0}))
+	_, err := p.Decide(ctx, BidRequest{})
+	assert.ErrorContains(t, err, "bot didn't reply within 100ms")
+}
+
+func TestExecPlayerStartFails(t *testing.T) {
+	ctx := context.Background()
+	p := &ExecPlayer{Path: filepath.Join(t.TempDir(), "missing")}
+	assert.ErrorContains(t, p.Notify(ctx, PlayerNumEvent{0}), "starting bot")
+	assert.NoError(t, p.Close())
+
+	// The program exits before the handshake
+	p = helperBot(t, "early")
+	assert.ErrorContains(t, p.Notify(ctx, PlayerNumEvent{0}), "bot exited: exit status 4")
+	assert.NoError(t, p.Close())
+	assert.ErrorContains(t, p.Start(ctx), "bot exited: exit status 4")
+	assert.NoError(t, p.Close())
+}
+
+func TestParseCard(t *testing.T) {
+	for _, cd := range *game.GetDeck() {
+		parsed, err := parseCard(encodeCard(cd))
+		assert.NoError(t, err)
+		assert.Equal(t, cd, parsed)
+	}
+	_, err := parseCard("3S")
+	assert.Error(t, err)
+}
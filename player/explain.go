@@ -0,0 +1,201 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Explanation describes why a bot made a decision, to help debug bots. Cards
+// and bids are written as in the ExecPlayer protocol, e.g. "10H" or "7NT".
+type Explanation struct {
+	Seat int `json:"seat"`
+	// Kind is the kind of decision: "bid", "discard", "play" or "jokersuit".
+	Kind   string `json:"kind"`
+	Choice string `json:"choice"`
+	// Candidates are the options the bot scored, best first. The meaning of
+	// the scores depends on the bot, but higher is always better.
+	Candidates []Candidate `json:"candidates,omitempty"`
+	// Samples is the number of deals or games simulated to decide.
+	Samples int `json:"samples,omitempty"`
+	// Rules are the heuristic rules which fired, in order.
+	Rules []string `json:"rules,omitempty"`
+	// Holdings are what the bot inferred about the other players' hands.
+	Holdings []Holding `json:"holdings,omitempty"`
+}
+
+// Candidate is an option scored by a bot.
+type Candidate struct {
+	Action string  `json:"action"`
+	Score  float64 `json:"score"`
+	// Samples is the number of simulations which tried the option, if it
+	// was scored by simulation.
+	Samples int `json:"samples,omitempty"`
+}
+
+// Holding is what a bot inferred about another player's hand.
+type Holding struct {
+	Player int      `json:"player"`
+	Voids  []string `json:"voids,omitempty"`
+	// Likely are the unseen cards the player probably holds, most likely
+	// first.
+	Likely []CardChance `json:"likely,omitempty"`
+}
+
+// CardChance is the probability that a player holds a card.
+type CardChance struct {
+	Card string  `json:"card"`
+	P    float64 `json:"p"`
+}
+
+// likelyChance is the probability above which a card is listed in a Holding.
+const likelyChance = 0.5
+
+// Explainer is an optional interface for a Player or PlayerV2 which can
+// explain its decisions.
+type Explainer interface {
+	// Explain explains the last decision, or returns nil if it can't. It is
+	// called straight after the decision, before any more events.
+	Explain() *Explanation
+}
+
+// String formats the explanation over several lines, for display.
+func (e *Explanation) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Player %d %s %s", e.Seat, e.Kind, e.Choice)
+	if len(e.Rules) > 0 {
+		fmt.Fprintf(&sb, ": %s", strings.Join(e.Rules, "; "))
+	}
+	if e.Samples > 0 {
+		fmt.Fprintf(&sb, " (%d samples)", e.Samples)
+	}
+	if len(e.Candidates) > 0 {
+		sb.WriteString("\n  candidates:")
+		for _, c := range e.Candidates {
+			fmt.Fprintf(&sb, " %s=%.2f", c.Action, c.Score)
+		}
+	}
+	for _, h := range e.Holdings {
+		if len(h.Voids) == 0 && len(h.Likely) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n  player %d:", h.Player)
+		if len(h.Voids) > 0 {
+			fmt.Fprintf(&sb, " void in %s;", strings.Join(h.Voids, ","))
+		}
+		for _, cc := range h.Likely {
+			fmt.Fprintf(&sb, " %s %.0f%%", cc.Card, 100*cc.P)
+		}
+	}
+	return sb.String()
+}
+
+// holdings summarises what the inference says about the other players'
+// hands.
+func (in *Inference) holdings() []Holding {
+	probs := in.Probabilities()
+	var hs []Holding
+	for pl := 0; pl < 4; pl++ {
+		if pl == in.seat || in.sitsOut(pl) {
+			continue
+		}
+		h := Holding{Player: pl}
+		for _, s := range suits {
+			if in.Void(pl, s) {
+				h.Voids = append(h.Voids, suitLetters[s])
+			}
+		}
+		for cd, p := range probs {
+			if p[pl] >= likelyChance {
+				h.Likely = append(h.Likely, CardChance{encodeCard(cd), p[pl]})
+			}
+		}
+		sort.Slice(h.Likely, func(i, j int) bool {
+			if h.Likely[i].P != h.Likely[j].P {
+				return h.Likely[i].P > h.Likely[j].P
+			}
+			return h.Likely[i].Card < h.Likely[j].Card
+		})
+		hs = append(hs, h)
+	}
+	return hs
+}
+
+// sortCandidates puts the candidates in order, best first.
+func sortCandidates(cs []Candidate) {
+	sort.SliceStable(cs, func(i, j int) bool { return cs[i].Score > cs[j].Score })
+}
+
+// DebugPane keeps the latest explanation from each bot, so that they can be
+// shown on a HumanPlayer's board. It is safe for concurrent use.
+type DebugPane struct {
+	mu   sync.Mutex
+	last [4]*Explanation
+}
+
+// Add records an explanation.
+func (d *DebugPane) Add(e *Explanation) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if e.Seat >= 0 && e.Seat < 4 {
+		d.last[e.Seat] = e
+	}
+}
+
+// String lists the latest explanation from each bot.
+func (d *DebugPane) String() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var lines []string
+	for _, e := range d.last {
+		if e != nil {
+			lines = append(lines, e.String())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Explained wraps a player, passing the explanation of each of its decisions
+// to f, if it is an Explainer.
+func Explained(p PlayerV2, f func(*Explanation)) PlayerV2 {
+	return &explained{p, f}
+}
+
+type explained struct {
+	PlayerV2
+	f func(*Explanation)
+}
+
+func (p *explained) Decide(ctx context.Context, r Request) (Response, error) {
+	resp, err := p.PlayerV2.Decide(ctx, r)
+	if err == nil {
+		if e := ExplainDecision(p.PlayerV2, r); e != nil {
+			p.f(e)
+		}
+	}
+	return resp, err
+}
+
+func (p *explained) Explain() *Explanation {
+	if ex, ok := p.PlayerV2.(Explainer); ok {
+		return ex.Explain()
+	}
+	return nil
+}
+
+// ExplainDecision returns the explanation of the decision p just made in
+// response to r, or nil if there isn't one. Only bids, discards, plays and
+// naming the Joker's suit are explained.
+func ExplainDecision(p PlayerV2, r Request) *Explanation {
+	switch r.(type) {
+	case BidRequest, DiscardRequest, PlayRequest, JokerSuitRequest:
+	default:
+		return nil
+	}
+	if ex, ok := p.(Explainer); ok {
+		return ex.Explain()
+	}
+	return nil
+}
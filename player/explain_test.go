@@ -0,0 +1,131 @@
+package player
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/util"
+	"github.com/stretchr/testify/assert"
+
+	c "github.com/barrettj12/collections"
+)
+
+func TestHeuristicExplain(t *testing.T) {
+	p := &HeuristicPlayer{}
+	assert.Nil(t, p.Explain())
+
+	p.NotifyPlayerNum(3)
+	h := hand(
+		card.Card{7, card.Spades}, card.Card{card.King, card.Spades}, card.Card{4, card.Hearts}, card.Card{9, card.Hearts},
+		card.Card{5, card.Clubs}, card.Card{8, card.Clubs}, card.Card{card.Queen, card.Clubs}, card.Card{6, card.Diamonds},
+		card.Card{9, card.Diamonds}, card.Card{card.Ace, card.Diamonds},
+	)
+	bid := game.SuitBid{7, card.Hearts}
+	bid.SortHand(h)
+	p.NotifyHand(h)
+
+	p.NotifyBid(0, bid)
+	p.NotifyBid(1, game.Pass{})
+	p.NotifyBid(2, game.Pass{})
+	b := p.Bid()
+	e := p.Explain()
+	assert.Equal(t, 3, e.Seat)
+	assert.Equal(t, "bid", e.Kind)
+	assert.Equal(t, encodeBid(b), e.Choice)
+	assert.Len(t, e.Candidates, 5)
+	for i := 1; i < len(e.Candidates); i++ {
+		assert.GreaterOrEqual(t, e.Candidates[i-1].Score, e.Candidates[i].Score)
+	}
+	assert.NotEmpty(t, e.Rules)
+
+	p.NotifyBid(3, game.Pass{})
+	p.NotifyBidWinner(0, bid)
+	trick := c.AsList([]game.PlayInfo{
+		{0, card.Card{4, card.Spades}},
+		{1, card.Card{5, card.Spades}},
+		{2, card.Card{6, card.Diamonds}},
+	})
+	for _, pl := range *trick {
+		p.NotifyPlay(pl.Player, pl.Card)
+	}
+	i := p.Play(trick, bid.ValidPlays(trick, p.Hand))
+	e = p.Explain()
+	assert.Equal(t, "play", e.Kind)
+	assert.Equal(t, encodeCard(util.E(p.Hand.Get(i))), e.Choice)
+	assert.Equal(t, strings.Join(e.Rules, ": "), p.Reason())
+
+	// Player 2 showed out of spades
+	if assert.Len(t, e.Holdings, 3) {
+		for j, hd := range e.Holdings {
+			assert.Equal(t, j, hd.Player)
+		}
+		assert.Equal(t, []string{"S"}, e.Holdings[2].Voids)
+		assert.Empty(t, e.Holdings[0].Voids)
+	}
+	assert.Contains(t, e.String(), "player 2: void in S;")
+
+	var pane DebugPane
+	pane.Add(e)
+	assert.Equal(t, e.String(), pane.String())
+}
+
+func TestHeuristicReasonSupport(t *testing.T) {
+	p := &HeuristicPlayer{}
+	p.NotifyPlayerNum(2)
+	p.NotifyHand(hand(
+		card.JokerCard, card.Card{card.Jack, card.Hearts}, card.Card{card.Ace, card.Hearts},
+		card.Card{9, card.Hearts}, card.Card{7, card.Hearts}, card.Card{card.Ace, card.Spades},
+		card.Card{5, card.Spades}, card.Card{6, card.Clubs}, card.Card{8, card.Clubs}, card.Card{8, card.Diamonds},
+	))
+	p.NotifyBid(0, game.SuitBid{6, card.Hearts})
+	p.NotifyBid(1, game.SuitBid{7, card.Spades})
+
+	assert.Equal(t, game.SuitBid{7, card.Hearts}, p.Bid())
+	assert.Equal(t, []string{"support partner's bid", "the hand should take about 7.2 tricks in hearts"}, p.Explain().Rules)
+	assert.Equal(t, "support partner's bid: the hand should take about 7.2 tricks in hearts", p.Reason())
+}
+
+func TestSimulationExplain(t *testing.T) {
+	h := hand(
+		card.JokerCard, card.Card{card.Jack, card.Hearts}, card.Card{card.Jack, card.Diamonds},
+		card.Card{card.Ace, card.Hearts}, card.Card{9, card.Hearts}, card.Card{7, card.Hearts},
+		card.Card{card.Ace, card.Spades}, card.Card{5, card.Spades},
+		card.Card{6, card.Clubs}, card.Card{8, card.Diamonds},
+	)
+	bid := game.SuitBid{7, card.Hearts}
+	bid.SortHand(h)
+	valid := c.AsList([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+
+	// The heuristic tie-break doesn't add its rule to the explanation
+	pimc := &PIMCPlayer{Samples: 4, Workers: 1, Rand: rand.New(rand.NewSource(1))}
+	pimc.NotifyPlayerNum(0)
+	pimc.NotifyHand(h)
+	pimc.NotifyBidWinner(0, bid)
+	pimc.Play(c.NewList[game.PlayInfo](0), valid)
+	assert.Equal(t, []string{"best on average over 4 possible deals"}, pimc.Explain().Rules)
+
+	ismcts := &ISMCTSPlayer{Iterations: 50, Rand: rand.New(rand.NewSource(1))}
+	ismcts.NotifyPlayerNum(0)
+	ismcts.NotifyHand(h)
+	ismcts.NotifyBidWinner(0, bid)
+	ismcts.Play(c.NewList[game.PlayInfo](0), valid)
+	assert.Equal(t, []string{"did best in 50 simulated games"}, ismcts.Explain().Rules)
+}
+
+func TestErringPlayerExplain(t *testing.T) {
+	p := &erringPlayer{Player: &HeuristicPlayer{}, rate: 1, rand: rand.New(rand.NewSource(1))}
+	bid := game.NoTrumpsBid{Tricks: 6}
+	p.NotifyPlayerNum(0)
+	p.NotifyHand(hand(card.Card{4, card.Spades}, card.Card{card.Ace, card.Spades}, card.Card{5, card.Clubs}))
+	p.NotifyBidWinner(0, bid)
+
+	for n := 0; n < 10; n++ {
+		trick := c.NewList[game.PlayInfo](4)
+		i := p.Play(trick, bid.ValidPlays(trick, p.hand))
+		e := p.Explain()
+		assert.Equal(t, encodeCard(util.E(p.hand.Get(i))), e.Choice)
+	}
+}
@@ -0,0 +1,491 @@
+package player
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+
+	c "github.com/barrettj12/collections"
+)
+
+// Weights are the tunable parameters used by HeuristicPlayer to estimate
+// how many tricks a hand will take, and to choose its plays. Each bidding
+// weight is a number of tricks.
+type Weights struct {
+	// Trump honours
+	Joker      float64
+	RightBower float64
+	LeftBower  float64
+	TrumpAce   float64
+	TrumpKing  float64
+	// TrumpLength is added for each trump beyond the third.
+	TrumpLength float64
+	// Side-suit honours. Kings only count if they are guarded.
+	SideAce  float64
+	SideKing float64
+
+	// No trumps
+	NoTrumpsAce  float64
+	NoTrumpsKing float64
+	// NoTrumpsLength is added for each card beyond the fourth in a suit
+	// headed by the ace.
+	NoTrumpsLength float64
+
+	// Support is the number of tricks expected from the kitty and partner.
+	Support float64
+	// PartnerSuit is added to the estimate for a suit the partner has bid.
+	PartnerSuit float64
+
+	// MisereRisk is the number of risky cards (cards which might be forced
+	// to win a trick) allowed in a misère hand.
+	MisereRisk int
+
+	// Card play
+	// DrawTrumps is the fewest trumps the contractor leads to draw the
+	// opponents' trumps.
+	DrawTrumps int
+	// CoverRank is the lowest rank of a led card which second hand covers
+	// (ace 14, Joker 15). Below it, or if 0, second hand plays low.
+	CoverRank int
+	// RuffLength is the longest side suit led to set up ruffs in a suit
+	// contract. If every side suit is longer, the longest is led instead.
+	RuffLength int
+}
+
+// DefaultWeights are the weights used by HeuristicPlayer if none are given.
+var DefaultWeights = Weights{
+	Joker:          1,
+	RightBower:     1,
+	LeftBower:      0.9,
+	TrumpAce:       0.8,
+	TrumpKing:      0.5,
+	TrumpLength:    0.8,
+	SideAce:        0.8,
+	SideKing:       0.3,
+	NoTrumpsAce:    0.9,
+	NoTrumpsKing:   0.4,
+	NoTrumpsLength: 0.5,
+	Support:        2,
+	PartnerSuit:    1,
+	MisereRisk:     0,
+	DrawTrumps:     1,
+	CoverRank:      0,
+	RuffLength:     13,
+}
+
+var suits = []card.Suit{card.Spades, card.Clubs, card.Diamonds, card.Hearts}
+
+// HeuristicPlayer is a computer player which bids by estimating the tricks its
+// hand will take in each denomination, counting bowers, the Joker, trump
+// length and side-suit aces and kings. It plays using standard card-play
+// heuristics (see choosePlay), based only on what it has been notified of.
+type HeuristicPlayer struct {
+	BotBase
+
+	// Weights are used to estimate the value of a hand and to choose plays.
+	// If nil, DefaultWeights are used.
+	Weights *Weights
+	// Table, if set, is used to estimate the tricks a hand will take instead
+	// of the weights.
+	Table *BidTable
+
+	expl Explanation // of the last decision
+}
+
+// HeuristicPlayer implements Player, Paced, Advisor and Explainer.
+var _ Player = &HeuristicPlayer{}
+var _ Paced = &HeuristicPlayer{}
+var _ Advisor = &HeuristicPlayer{}
+var _ Explainer = &HeuristicPlayer{}
+
+func (p *HeuristicPlayer) Reason() string {
+	return strings.Join(p.expl.Rules, ": ")
+}
+
+func (p *HeuristicPlayer) Explain() *Explanation {
+	if p.expl.Kind == "" {
+		return nil
+	}
+	e := p.expl
+	e.Holdings = p.Inference.holdings()
+	return &e
+}
+
+// explain starts the explanation of a decision.
+func (p *HeuristicPlayer) explain(kind string) {
+	p.expl = Explanation{Seat: p.Seat, Kind: kind}
+}
+
+// rule adds a rule which fired to the explanation.
+func (p *HeuristicPlayer) rule(r string) {
+	p.expl.Rules = append(p.expl.Rules, r)
+}
+
+// chose records the card played in the explanation, and returns its index.
+func (p *HeuristicPlayer) chose(i int) int {
+	p.expl.Choice = encodeCard(p.card(i))
+	return i
+}
+
+// partnerSuit returns the suit the partner last bid, or else the suit they
+// first led, or card.NoSuit if neither is known.
+func (p *HeuristicPlayer) partnerSuit() card.Suit {
+	pn := partner(p.Seat)
+	bids := p.Bids[pn]
+	for i := len(bids) - 1; i >= 0; i-- {
+		if b, ok := bids[i].(game.SuitBid); ok {
+			return b.TrumpSuit
+		}
+	}
+	for _, t := range append(p.Tricks, p.Trick) {
+		if len(t) > 0 && t[0].Player == pn && t[0].Card != card.JokerCard {
+			return p.Contract.Suit(t[0].Card)
+		}
+	}
+	return card.NoSuit
+}
+
+func (p *HeuristicPlayer) weights() Weights {
+	if p.Weights == nil {
+		return DefaultWeights
+	}
+	return *p.Weights
+}
+
+func (p *HeuristicPlayer) Bid() game.Bid {
+	p.Pause()
+	p.explain("bid")
+	est := func(hand *c.List[card.Card], bid game.Bid) float64 { return EstimateTricks(hand, p.weights(), bid) }
+	if p.Table != nil {
+		est = p.Table.ExpectedTricks
+	}
+	partnerBid := p.LastBid(partner(p.Seat))
+	bid := ChooseBid(p.Hand, p.weights(), est, p.HighBid, p.HighBidder == partner(p.Seat), partnerBid)
+
+	for _, b := range []game.Bid{
+		game.SuitBid{6, card.Spades}, game.SuitBid{6, card.Clubs}, game.SuitBid{6, card.Diamonds},
+		game.SuitBid{6, card.Hearts}, game.NoTrumpsBid{Tricks: 6},
+	} {
+		p.expl.Candidates = append(p.expl.Candidates, Candidate{Action: denomination(b), Score: est(p.Hand, b)})
+	}
+	sortCandidates(p.expl.Candidates)
+
+	switch b := bid.(type) {
+	case game.Pass:
+		if p.HighBid != nil {
+			p.rule(fmt.Sprintf("the hand isn't worth more than %s", encodeBid(p.HighBid)))
+		} else {
+			p.rule("the hand isn't worth a bid")
+		}
+	case game.MisereBid:
+		p.rule("few cards which could be forced to win a trick")
+	default:
+		if partnerBid != nil && sameDenomination(b, partnerBid) {
+			p.rule("support partner's bid")
+		}
+		p.rule(fmt.Sprintf("the hand should take about %.1f tricks in %s", est(p.Hand, b), denomination(b)))
+	}
+	p.expl.Choice = encodeBid(bid)
+	return bid
+}
+
+// denomination returns the name of a bid's denomination, e.g. "hearts".
+func denomination(bid game.Bid) string {
+	if b, ok := bid.(game.SuitBid); ok {
+		return strings.ToLower(string(b.TrumpSuit))
+	}
+	return "no trumps"
+}
+
+// An Estimator estimates the number of tricks a hand will take if it wins the
+// contract in the given denomination (a SuitBid or NoTrumpsBid), including
+// the support expected from the kitty and partner.
+type Estimator func(hand *c.List[card.Card], bid game.Bid) float64
+
+// ChooseBid picks a bid for the given hand, or game.Pass{}. highBid is the
+// highest bid so far (nil if none), and partnerHigh says whether the partner
+// made it. partnerBid is the partner's last bid (nil if none). Tricks are
+// estimated by est, or by EstimateTricks with the weights if est is nil.
+//
+// The bid is made in the denomination with the highest potential value,
+// at the cheapest level which beats highBid. When the partner holds the
+// contract, it is only overcalled to support their suit, or if the hand is
+// worth at least a trick more.
+func ChooseBid(hand *c.List[card.Card], w Weights, est Estimator, highBid game.Bid, partnerHigh bool, partnerBid game.Bid) game.Bid {
+	if est == nil {
+		est = func(hand *c.List[card.Card], bid game.Bid) float64 { return EstimateTricks(hand, w, bid) }
+	}
+	high := 0
+	if highBid != nil {
+		high = highBid.Value()
+	}
+
+	var best game.Bid
+	bestValue := 0
+	consider := func(bid game.Bid, maxValue int) {
+		if bid == nil || maxValue <= bestValue {
+			return
+		}
+		if partnerHigh && !sameDenomination(bid, partnerBid) && maxValue < high+100 {
+			return
+		}
+		best, bestValue = bid, maxValue
+	}
+
+	for _, s := range suits {
+		e := est(hand, game.SuitBid{TrumpSuit: s})
+		if sb, ok := partnerBid.(game.SuitBid); ok && sb.TrumpSuit == s {
+			e += w.PartnerSuit
+		}
+		tricks := maxTricks(e)
+		consider(cheapestBid(high, tricks, func(n int) game.Bid { return game.SuitBid{n, s} }),
+			game.SuitBid{tricks, s}.Value())
+	}
+
+	e := est(hand, game.NoTrumpsBid{})
+	if _, ok := partnerBid.(game.NoTrumpsBid); ok {
+		e += w.PartnerSuit
+	}
+	if tricks := maxTricks(e); stoppers(hand) >= 3 {
+		consider(cheapestBid(high, tricks, func(n int) game.Bid { return game.NoTrumpsBid{Tricks: n} }),
+			game.NoTrumpsBid{Tricks: tricks}.Value())
+	}
+
+	for _, open := range []bool{false, true} {
+		bid := game.MisereBid{Open: open}
+		if bid.Value() > high && MisereRisk(hand, open) <= w.MisereRisk {
+			consider(bid, bid.Value())
+		}
+	}
+
+	if best == nil {
+		return game.Pass{}
+	}
+	return best
+}
+
+// EstimateTricks estimates the number of tricks the hand will take if it
+// wins the contract in the given denomination (a SuitBid or NoTrumpsBid),
+// including the support expected from the kitty and partner.
+func EstimateTricks(hand *c.List[card.Card], w Weights, bid game.Bid) float64 {
+	est := w.Support
+	bySuit := splitSuits(hand, bid)
+
+	switch b := bid.(type) {
+	case game.SuitBid:
+		trumps := bySuit[b.TrumpSuit]
+		for _, cd := range trumps {
+			switch {
+			case cd == card.JokerCard:
+				est += w.Joker
+			case cd == card.Card{card.Jack, b.TrumpSuit}:
+				est += w.RightBower
+			case cd.Rank == card.Jack:
+				est += w.LeftBower
+			case cd.Rank == card.Ace:
+				est += w.TrumpAce
+			case cd.Rank == card.King:
+				est += w.TrumpKing
+			}
+		}
+		if len(trumps) > 3 {
+			est += w.TrumpLength * float64(len(trumps)-3)
+		}
+		for _, s := range suits {
+			if s == b.TrumpSuit {
+				continue
+			}
+			est += sideHonours(bySuit[s], w.SideAce, w.SideKing)
+		}
+
+	case game.NoTrumpsBid:
+		if hand.Contains(card.JokerCard) {
+			est += w.Joker
+		}
+		for _, s := range suits {
+			cards := bySuit[s]
+			est += sideHonours(cards, w.NoTrumpsAce, w.NoTrumpsKing)
+			if len(cards) > 4 && containsRank(cards, card.Ace) {
+				est += w.NoTrumpsLength * float64(len(cards)-4)
+			}
+		}
+	}
+	return est
+}
+
+// MisereRisk counts the cards in the hand which might be forced to win a
+// trick in misère. A card is safe if there are enough lower cards in its suit
+// to duck underneath it; open misère needs a bigger margin, as the defenders
+// can see the hand. The Joker always counts as risky.
+func MisereRisk(hand *c.List[card.Card], open bool) int {
+	slack := 6
+	if open {
+		slack = 5
+	}
+
+	risk := 0
+	for _, cards := range splitSuits(hand, game.NoTrumpsBid{}) {
+		ranks := make([]int, 0, len(cards))
+		for _, cd := range cards {
+			ranks = append(ranks, rankValue(cd))
+		}
+		sort.Ints(ranks)
+		for k, r := range ranks {
+			if r > slack+2*k {
+				risk++
+			}
+		}
+	}
+	if hand.Contains(card.JokerCard) {
+		risk++
+	}
+	return risk
+}
+
+func (p *HeuristicPlayer) Discard() *c.List[card.Card] {
+	p.Pause()
+	p.explain("discard")
+	switch p.Contract.(type) {
+	case game.MisereBid:
+		p.rule("shed the cards most likely to win a trick")
+	case game.SuitBid:
+		p.rule("keep trumps and aces, and void short side suits")
+	default:
+		p.rule("keep the Joker, stoppers and long suits")
+	}
+	discards := PlanDiscard(p.Hand, p.Contract)
+	p.expl.Choice = encodeCards(discards)
+	return discards
+}
+
+func (p *HeuristicPlayer) Play(trick *c.List[game.PlayInfo], validPlays *c.List[int]) int {
+	p.Pause()
+	p.explain("play")
+	return p.chose(p.choosePlay(validPlays))
+}
+
+// JokerSuit picks the suit the player holds most of.
+func (p *HeuristicPlayer) JokerSuit() card.Suit {
+	p.Pause()
+	p.explain("jokersuit")
+	bySuit := splitSuits(p.Hand, game.NoTrumpsBid{})
+	best := suits[0]
+	for _, s := range suits {
+		if len(bySuit[s]) > len(bySuit[best]) {
+			best = s
+		}
+		p.expl.Candidates = append(p.expl.Candidates, Candidate{Action: suitLetters[s], Score: float64(len(bySuit[s]))})
+	}
+	sortCandidates(p.expl.Candidates)
+	p.rule("name the suit you hold most of")
+	p.expl.Choice = suitLetters[best]
+	return best
+}
+
+// partner returns the seat of the given player's partner.
+func partner(seat int) int {
+	return (seat + 2) % 4
+}
+
+// maxTricks returns the number of tricks which can be bid on an estimate,
+// or 0 if the estimate is too low to bid.
+func maxTricks(est float64) int {
+	tricks := int(math.Floor(est))
+	if tricks < 6 {
+		return 0
+	}
+	if tricks > 10 {
+		return 10
+	}
+	return tricks
+}
+
+// cheapestBid returns the bid with the fewest tricks (up to most) which
+// beats the given value, or nil if there is none.
+func cheapestBid(high, most int, bid func(tricks int) game.Bid) game.Bid {
+	for n := 6; n <= most; n++ {
+		if b := bid(n); b.Value() > high {
+			return b
+		}
+	}
+	return nil
+}
+
+// sameDenomination returns true if both bids have the same trump suit (or
+// are both no trumps).
+func sameDenomination(a, b game.Bid) bool {
+	switch a := a.(type) {
+	case game.SuitBid:
+		b, ok := b.(game.SuitBid)
+		return ok && a.TrumpSuit == b.TrumpSuit
+	case game.NoTrumpsBid:
+		_, ok := b.(game.NoTrumpsBid)
+		return ok
+	}
+	return false
+}
+
+// splitSuits groups the hand's cards by suit, according to the bid. The Joker
+// is only included if the bid gives it a suit.
+func splitSuits(hand *c.List[card.Card], bid game.Bid) map[card.Suit][]card.Card {
+	bySuit := map[card.Suit][]card.Card{}
+	for _, cd := range *hand {
+		s := bid.Suit(cd)
+		if s != card.NoSuit {
+			bySuit[s] = append(bySuit[s], cd)
+		}
+	}
+	return bySuit
+}
+
+// sideHonours values the aces and guarded kings in a suit.
+func sideHonours(cards []card.Card, ace, king float64) float64 {
+	v := 0.0
+	if containsRank(cards, card.Ace) {
+		v += ace
+	}
+	if containsRank(cards, card.King) && len(cards) >= 2 {
+		v += king
+	}
+	return v
+}
+
+// stoppers counts the suits which are stopped in no trumps, by an ace or a
+// guarded king. The Joker stops one more suit.
+func stoppers(hand *c.List[card.Card]) int {
+	n := 0
+	for _, cards := range splitSuits(hand, game.NoTrumpsBid{}) {
+		if containsRank(cards, card.Ace) || (containsRank(cards, card.King) && len(cards) >= 2) {
+			n++
+		}
+	}
+	if hand.Contains(card.JokerCard) {
+		n++
+	}
+	return n
+}
+
+func containsRank(cards []card.Card, r card.Rank) bool {
+	for _, cd := range cards {
+		if cd.Rank == r {
+			return true
+		}
+	}
+	return false
+}
+
+// rankValue orders cards within a suit, with aces high.
+func rankValue(cd card.Card) int {
+	switch cd.Rank {
+	case card.Ace:
+		return 14
+	case card.Joker:
+		return 15
+	default:
+		return int(cd.Rank)
+	}
+}
@@ -0,0 +1,129 @@
+package player
+
+import (
+	"testing"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/util"
+	"github.com/stretchr/testify/assert"
+
+	c "github.com/barrettj12/collections"
+)
+
+func hand(cards ...card.Card) *c.List[card.Card] {
+	h := c.NewList[card.Card](len(cards))
+	h.Append(cards...)
+	return h
+}
+
+func TestChooseBid(t *testing.T) {
+	strongHearts := hand(
+		card.JokerCard, card.Card{card.Jack, card.Hearts}, card.Card{card.Jack, card.Diamonds},
+		card.Card{card.Ace, card.Hearts}, card.Card{9, card.Hearts}, card.Card{7, card.Hearts},
+		card.Card{card.Ace, card.Spades}, card.Card{5, card.Spades},
+		card.Card{6, card.Clubs}, card.Card{8, card.Diamonds},
+	)
+	weak := hand(
+		card.Card{card.Queen, card.Hearts}, card.Card{9, card.Hearts}, card.Card{7, card.Spades},
+		card.Card{card.King, card.Spades}, card.Card{card.Queen, card.Spades}, card.Card{10, card.Clubs},
+		card.Card{card.Jack, card.Clubs}, card.Card{9, card.Diamonds}, card.Card{card.Queen, card.Diamonds},
+		card.Card{card.King, card.Clubs},
+	)
+	low := hand(
+		card.Card{5, card.Spades}, card.Card{6, card.Spades}, card.Card{7, card.Spades},
+		card.Card{5, card.Clubs}, card.Card{7, card.Clubs},
+		card.Card{4, card.Diamonds}, card.Card{6, card.Diamonds},
+		card.Card{4, card.Hearts}, card.Card{5, card.Hearts}, card.Card{8, card.Hearts},
+	)
+	w := DefaultWeights
+
+	// Opens at the cheapest level, in its best suit
+	assert.Equal(t, game.SuitBid{6, card.Hearts}, ChooseBid(strongHearts, w, nil, nil, false, nil))
+	// Overcalls an opponent
+	assert.Equal(t, game.SuitBid{7, card.Hearts}, ChooseBid(strongHearts, w, nil, game.SuitBid{7, card.Spades}, false, nil))
+	// Doesn't bid beyond its estimate
+	assert.Equal(t, game.Pass{}, ChooseBid(strongHearts, w, nil, game.SuitBid{9, card.Spades}, false, nil))
+	// Doesn't overcall its partner in a weaker denomination
+	assert.Equal(t, game.Pass{}, ChooseBid(strongHearts, w, nil, game.SuitBid{8, card.Clubs}, true, game.SuitBid{8, card.Clubs}))
+
+	assert.Equal(t, game.Pass{}, ChooseBid(weak, w, nil, nil, false, nil))
+	assert.Equal(t, game.MisereBid{Open: true}, ChooseBid(low, w, nil, nil, false, nil))
+	// A lone 10 might be forced to win a trick
+	util.E0(low.Set(4, card.Card{10, card.Clubs}))
+	assert.Equal(t, 1, MisereRisk(low, false))
+	assert.Equal(t, game.Pass{}, ChooseBid(low, w, nil, nil, false, nil))
+}
+
+func TestHeuristicPlay(t *testing.T) {
+	bid := game.SuitBid{7, card.Hearts}
+	newPlayer := func(seat int, cards ...card.Card) *HeuristicPlayer {
+		p := &HeuristicPlayer{}
+		p.NotifyPlayerNum(seat)
+		h := hand(cards...)
+		bid.SortHand(h)
+		p.NotifyHand(h)
+		p.NotifyBid(0, bid)
+		p.NotifyBidWinner(0, bid)
+		return p
+	}
+	play := func(p *HeuristicPlayer, plays ...game.PlayInfo) card.Card {
+		trick := c.NewList[game.PlayInfo](4)
+		for _, pl := range plays {
+			p.NotifyPlay(pl.Player, pl.Card)
+			trick.Append(pl)
+		}
+		return util.E(p.Hand.Get(p.Play(trick, bid.ValidPlays(trick, p.Hand))))
+	}
+
+	// Contractor draws trumps
+	p := newPlayer(0, card.Card{card.Jack, card.Hearts}, card.Card{5, card.Hearts}, card.Card{card.Ace, card.Spades})
+	assert.Equal(t, card.Card{card.Jack, card.Hearts}, play(p))
+	assert.Equal(t, "draw trumps", p.Reason())
+
+	// Second hand low
+	p = newPlayer(1, card.Card{card.King, card.Spades}, card.Card{6, card.Spades})
+	assert.Equal(t, card.Card{6, card.Spades}, play(p, game.PlayInfo{0, card.Card{9, card.Spades}}))
+	assert.Equal(t, "second hand plays low", p.Reason())
+
+	// Third hand high
+	p = newPlayer(2, card.Card{card.Ace, card.Spades}, card.Card{6, card.Spades})
+	assert.Equal(t, card.Card{card.Ace, card.Spades}, play(p,
+		game.PlayInfo{0, card.Card{9, card.Spades}}, game.PlayInfo{1, card.Card{10, card.Spades}}))
+	assert.Equal(t, "third hand plays high", p.Reason())
+
+	// Don't overtake or trump a partner who has the trick won
+	p = newPlayer(3, card.Card{5, card.Hearts}, card.Card{6, card.Clubs})
+	assert.Equal(t, card.Card{6, card.Clubs}, play(p,
+		game.PlayInfo{0, card.Card{9, card.Spades}}, game.PlayInfo{1, card.Card{card.Ace, card.Spades}},
+		game.PlayInfo{2, card.Card{10, card.Spades}}))
+	assert.Equal(t, "partner is winning, play low", p.Reason())
+
+	// A defender cashes a side-suit ace, even though the trumps aren't all
+	// seen, unless an opponent has shown out of the suit
+	p = newPlayer(1, card.Card{card.Ace, card.Clubs}, card.Card{7, card.Spades})
+	assert.Equal(t, card.Card{card.Ace, card.Clubs}, play(p))
+	assert.Equal(t, "cash a winner", p.Reason())
+
+	p = newPlayer(1, card.Card{card.Ace, card.Clubs}, card.Card{6, card.Clubs}, card.Card{7, card.Spades})
+	for _, pl := range []game.PlayInfo{{0, card.Card{4, card.Clubs}}, {1, card.Card{6, card.Clubs}}, {2, card.Card{5, card.Diamonds}}, {3, card.Card{8, card.Clubs}}} {
+		p.NotifyPlay(pl.Player, pl.Card)
+	}
+	p.NotifyTrickWinner(3)
+	p.NotifyHand(hand(card.Card{card.Ace, card.Clubs}, card.Card{7, card.Spades}))
+	assert.Equal(t, card.Card{7, card.Spades}, play(p))
+
+	// Card-play weights
+	w := DefaultWeights
+	w.CoverRank = 9
+	p = newPlayer(1, card.Card{card.King, card.Spades}, card.Card{6, card.Spades})
+	p.Weights = &w
+	assert.Equal(t, card.Card{card.King, card.Spades}, play(p, game.PlayInfo{0, card.Card{9, card.Spades}}))
+	assert.Equal(t, "cover a high card", p.Reason())
+
+	w.DrawTrumps = 3
+	p = newPlayer(0, card.Card{card.Jack, card.Hearts}, card.Card{5, card.Hearts}, card.Card{card.Ace, card.Spades})
+	p.Weights = &w
+	assert.Equal(t, card.Card{card.Ace, card.Spades}, play(p))
+	assert.NotEqual(t, "draw trumps", p.Reason())
+}
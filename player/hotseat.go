@@ -0,0 +1,48 @@
+package player
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/barrettj12/screen"
+)
+
+// HotSeat allows several HumanPlayers to share one terminal, passing it
+// around the table. Only the active player's hand and messages are shown.
+// Before another player is asked for a decision, the screen is blanked until
+// that player presses enter.
+//
+// To use hot-seat mode, set the same HotSeat on each HumanPlayer.
+type HotSeat struct {
+	active *HumanPlayer
+}
+
+// SeatName returns the compass name of the given seat.
+func SeatName(seat int) string {
+	return []string{"North", "East", "South", "West"}[seat]
+}
+
+// isActive reports whether this player is currently using the terminal.
+func (p *HumanPlayer) isActive() bool {
+	return p.HotSeat == nil || p.HotSeat.active == p
+}
+
+// takeSeat makes this player the active player, if it isn't already. The
+// screen is blanked and the user must press enter before the player's hand
+// is shown.
+func (p *HumanPlayer) takeSeat() {
+	if p.isActive() {
+		return
+	}
+
+	p.HotSeat.active = nil
+	screen.Clear()
+	screen.Update()
+	fmt.Printf("Pass to %s.\n", SeatName(p.seat))
+	pressToContinue()
+
+	p.HotSeat.active = p
+	p.redrawBoard()
+	fmt.Print(strings.Join(p.pending, ""))
+	p.pending = nil
+}
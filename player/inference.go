@@ -0,0 +1,320 @@
+package player
+
+import (
+	"math/rand"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/util"
+
+	c "github.com/barrettj12/collections"
+)
+
+// Inference tracks what a player can infer about the other players' hands
+// from the events of the hand. For each other player, it knows which cards
+// they can't hold (cards already seen, and suits they have shown out of),
+// and weighs the remaining cards by what their bids suggest.
+//
+// Bots can use it to sample possible deals, and it could be shown to
+// learners as a "cheat sheet". Events are passed on using the same methods
+// as the Player interface. NotifyPlayerNum must be called first, at the start
+// of each hand.
+type Inference struct {
+	seat       int
+	hand       *c.List[card.Card]
+	bids       [4][]game.Bid
+	contract   game.Bid
+	contractor int
+	discards   *c.List[card.Card]
+
+	played    *c.Set[card.Card]
+	trick     []game.PlayInfo
+	voids     [4]map[card.Suit]bool
+	cardsLeft [4]int
+}
+
+// out is the holder index for cards not in any player's hand: the kitty
+// discards, and the cards of the misère contractor's partner.
+const out = 4
+
+func (in *Inference) NotifyPlayerNum(n int) {
+	*in = Inference{
+		seat:       n,
+		contractor: -1,
+		played:     c.NewSet[card.Card](43),
+	}
+	for i := range in.voids {
+		in.voids[i] = map[card.Suit]bool{}
+		in.cardsLeft[i] = 10
+	}
+}
+
+func (in *Inference) NotifyHand(hand *c.List[card.Card]) { in.hand = hand }
+
+func (in *Inference) NotifyBid(player int, bid game.Bid) {
+	if (bid != game.Pass{}) {
+		in.bids[player] = append(in.bids[player], bid)
+	}
+}
+
+func (in *Inference) NotifyBidWinner(player int, bid game.Bid) {
+	in.contract = bid
+	in.contractor = player
+}
+
+// NotifyDiscards tells the contractor which cards they discarded.
+func (in *Inference) NotifyDiscards(cards *c.List[card.Card]) {
+	in.discards = cards
+}
+
+func (in *Inference) NotifyPlay(player int, cd card.Card) {
+	if len(in.trick) > 0 {
+		// Record a void if the player didn't follow suit. In no trumps,
+		// the Joker can be played at any time.
+		leadSuit := in.contract.Suit(in.trick[0].Card)
+		if s := in.contract.Suit(cd); s != leadSuit && s != card.NoSuit {
+			in.voids[player][leadSuit] = true
+		}
+	}
+	in.trick = append(in.trick, game.PlayInfo{Player: player, Card: cd})
+	in.cardsLeft[player]--
+}
+
+func (in *Inference) NotifyTrickWinner(player int) {
+	for _, pl := range in.trick {
+		in.played.Add(pl.Card)
+	}
+	in.trick = nil
+}
+
+// Void returns true if the player has shown out of the suit.
+func (in *Inference) Void(player int, suit card.Suit) bool {
+	return in.voids[player][suit]
+}
+
+// Seen returns true if the card has been played, or is in (or discarded from)
+// the player's hand.
+func (in *Inference) Seen(cd card.Card) bool {
+	if in.played.Contains(cd) || in.hand.Contains(cd) {
+		return true
+	}
+	if in.discards != nil && in.discards.Contains(cd) {
+		return true
+	}
+	for _, pl := range in.trick {
+		if pl.Card == cd {
+			return true
+		}
+	}
+	return false
+}
+
+// Unseen returns the cards the player hasn't seen.
+func (in *Inference) Unseen() []card.Card {
+	var unseen []card.Card
+	for _, cd := range *game.GetDeck() {
+		if !in.Seen(cd) {
+			unseen = append(unseen, cd)
+		}
+	}
+	return unseen
+}
+
+// capacity returns the number of unseen cards held by each player, and out
+// of play.
+func (in *Inference) capacity(unseen int) (room [5]int) {
+	for i := 0; i < 4; i++ {
+		if i != in.seat && !in.sitsOut(i) {
+			room[i] = in.cardsLeft[i]
+			unseen -= room[i]
+		}
+	}
+	room[out] = unseen
+	return room
+}
+
+// sitsOut returns true if the player is the misère contractor's partner.
+func (in *Inference) sitsOut(player int) bool {
+	_, misere := in.contract.(game.MisereBid)
+	return misere && player == partner(in.contractor)
+}
+
+// CanHold returns true if the player could hold the card.
+func (in *Inference) CanHold(player int, cd card.Card) bool {
+	if player == in.seat {
+		return in.hand.Contains(cd)
+	}
+	if in.Seen(cd) || in.sitsOut(player) || in.cardsLeft[player] == 0 {
+		return false
+	}
+	return in.contract == nil || !in.voids[player][in.contract.Suit(cd)]
+}
+
+// weight returns how likely the player is to hold the card compared to
+// other players, based on their bids.
+func (in *Inference) weight(player int, cd card.Card) float64 {
+	w := 1.0
+	if player == out {
+		return w
+	}
+	for _, b := range in.bids[player] {
+		w *= bidWeight(b, cd)
+	}
+	return w
+}
+
+// bidWeight says how much more likely a bidder is to hold a card than an
+// average player.
+func bidWeight(bid game.Bid, cd card.Card) float64 {
+	honour := cd == card.JokerCard || cd.Rank == card.Jack || cd.Rank == card.Ace || cd.Rank == card.King
+	switch b := bid.(type) {
+	case game.SuitBid:
+		if b.Suit(cd) == b.TrumpSuit {
+			if honour {
+				return 3
+			}
+			return 2
+		}
+		if cd.Rank == card.Ace {
+			return 1.3
+		}
+	case game.MisereBid:
+		switch {
+		case cd == card.JokerCard || rankValue(cd) >= 11:
+			return 0.3
+		case rankValue(cd) <= 7:
+			return 1.5
+		}
+	case game.NoTrumpsBid:
+		switch {
+		case cd == card.JokerCard || cd.Rank == card.Ace:
+			return 2.5
+		case cd.Rank == card.King:
+			return 1.5
+		}
+	}
+	return 1
+}
+
+// Probabilities returns, for each unseen card, the probability that each
+// player holds it. The probabilities are consistent with the number of cards
+// each player holds.
+func (in *Inference) Probabilities() map[card.Card][4]float64 {
+	unseen := in.Unseen()
+	room := in.capacity(len(unseen))
+
+	// Start from the bid weights, then alternately scale so that each card
+	// is held by someone, and each player holds the right number of cards
+	// (iterative proportional fitting).
+	p := make([][5]float64, len(unseen))
+	for i, cd := range unseen {
+		for j := range room {
+			if room[j] > 0 && (j == out || in.CanHold(j, cd)) {
+				p[i][j] = in.weight(j, cd)
+			}
+		}
+	}
+	for iter := 0; iter < 50; iter++ {
+		for i := range p {
+			sum := 0.0
+			for j := range p[i] {
+				sum += p[i][j]
+			}
+			for j := range p[i] {
+				if sum > 0 {
+					p[i][j] /= sum
+				}
+			}
+		}
+		for j := range room {
+			sum := 0.0
+			for i := range p {
+				sum += p[i][j]
+			}
+			for i := range p {
+				if sum > 0 {
+					p[i][j] *= float64(room[j]) / sum
+				}
+			}
+		}
+	}
+
+	probs := make(map[card.Card][4]float64, len(unseen))
+	for i, cd := range unseen {
+		var pr [4]float64
+		copy(pr[:], p[i][:4])
+		probs[cd] = pr
+	}
+	return probs
+}
+
+// Sample deals the unseen cards at random, consistent with what the player
+// knows and weighted by the other players' bids. The player's own hand is
+// included in the deal.
+func (in *Inference) Sample(r *rand.Rand) [4]*c.List[card.Card] {
+	unseen := in.Unseen()
+	for try := 0; try < 100; try++ {
+		if hands, ok := in.trySample(r, unseen, true); ok {
+			return hands
+		}
+	}
+	// The voids can't be satisfied (which shouldn't happen), so ignore them
+	hands, _ := in.trySample(r, unseen, false)
+	return hands
+}
+
+func (in *Inference) trySample(r *rand.Rand, unseen []card.Card, respectVoids bool) ([4]*c.List[card.Card], bool) {
+	var hands [4]*c.List[card.Card]
+	for i := range hands {
+		hands[i] = c.NewList[card.Card](10)
+	}
+	hands[in.seat] = in.hand.Copy()
+
+	room := in.capacity(len(unseen))
+	cards := c.AsList(append([]card.Card(nil), unseen...))
+	util.Shuffle(r, cards)
+	for _, cd := range *cards {
+		// Choose a holder with room for the card, weighted by their room
+		// and bids
+		var weights [5]float64
+		total := 0.0
+		for j := range room {
+			if room[j] == 0 {
+				continue
+			}
+			if j != out && respectVoids && in.contract != nil && in.voids[j][in.contract.Suit(cd)] {
+				continue
+			}
+			weights[j] = float64(room[j]) * in.weight(j, cd)
+			total += weights[j]
+		}
+		if total == 0 {
+			return hands, false
+		}
+
+		j := pick(weights, r.Float64()*total)
+		room[j]--
+		if j != out {
+			hands[j].Append(cd)
+		}
+	}
+	return hands, true
+}
+
+// pick returns the index of the weight which x falls in, when the weights are
+// laid end to end. If rounding leaves x past the end, the last index with a
+// positive weight is returned.
+func pick(weights [5]float64, x float64) int {
+	last := -1
+	for j, w := range weights {
+		if w == 0 {
+			continue
+		}
+		if x < w {
+			return j
+		}
+		x -= w
+		last = j
+	}
+	return last
+}
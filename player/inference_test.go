@@ -0,0 +1,94 @@
+package player
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInferenceSample(t *testing.T) {
+	bid := game.SuitBid{7, card.Hearts}
+	in := &Inference{}
+	in.NotifyPlayerNum(0)
+	h := hand(
+		card.JokerCard, card.Card{card.Jack, card.Hearts}, card.Card{card.Ace, card.Hearts},
+		card.Card{9, card.Hearts}, card.Card{7, card.Hearts}, card.Card{5, card.Hearts},
+		card.Card{card.Ace, card.Spades}, card.Card{5, card.Spades},
+		card.Card{6, card.Clubs}, card.Card{card.Queen, card.Clubs},
+	)
+	in.NotifyHand(h)
+	in.NotifyBidWinner(0, bid)
+
+	// Player 1 shows out of spades
+	in.NotifyPlay(0, util.E(h.Remove(6)))
+	in.NotifyPlay(1, card.Card{8, card.Diamonds})
+	in.NotifyPlay(2, card.Card{6, card.Spades})
+	in.NotifyPlay(3, card.Card{card.King, card.Spades})
+	in.NotifyTrickWinner(0)
+
+	// 43 cards - 9 in hand - 4 played
+	assert.Len(t, in.Unseen(), 30)
+	assert.False(t, in.CanHold(1, card.Card{card.Queen, card.Spades}))
+	assert.True(t, in.CanHold(2, card.Card{card.Queen, card.Spades}))
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		hands := in.Sample(r)
+		assert.Equal(t, h.Size(), hands[0].Size())
+		for j := 1; j < 4; j++ {
+			assert.Equal(t, 9, hands[j].Size())
+		}
+		for _, cd := range *hands[1] {
+			assert.NotEqual(t, card.Spades, bid.Suit(cd))
+		}
+	}
+}
+
+func TestInferenceProbabilities(t *testing.T) {
+	in := &Inference{}
+	in.NotifyPlayerNum(0)
+	in.NotifyHand(hand(
+		card.Card{4, card.Hearts}, card.Card{5, card.Hearts}, card.Card{6, card.Hearts},
+		card.Card{5, card.Spades}, card.Card{6, card.Spades}, card.Card{7, card.Spades},
+		card.Card{5, card.Clubs}, card.Card{6, card.Clubs},
+		card.Card{5, card.Diamonds}, card.Card{6, card.Diamonds},
+	))
+	in.NotifyBid(1, game.SuitBid{6, card.Spades})
+
+	probs := in.Probabilities()
+	assert.Len(t, probs, 33)
+
+	// Each player holds 10 of the 33 unseen cards
+	for j := 1; j < 4; j++ {
+		sum := 0.0
+		for _, p := range probs {
+			sum += p[j]
+		}
+		assert.InDelta(t, 10, sum, 0.01)
+	}
+	for _, p := range probs {
+		assert.Zero(t, p[0])
+		assert.LessOrEqual(t, p[1]+p[2]+p[3], 1.0001)
+	}
+
+	// The spades bidder probably holds the top spades
+	bower := probs[card.Card{card.Jack, card.Spades}]
+	assert.Greater(t, bower[1], 0.4)
+	assert.Greater(t, bower[1], bower[2])
+	assert.False(t, math.IsNaN(bower[3]))
+}
+
+func TestPick(t *testing.T) {
+	weights := [5]float64{0.5, 0, 1.5, 1, 0}
+	assert.Equal(t, 0, pick(weights, 0))
+	assert.Equal(t, 2, pick(weights, 0.5))
+	assert.Equal(t, 3, pick(weights, 2.9))
+	// Rounding can leave x past the total
+	assert.Equal(t, 3, pick(weights, 3))
+	assert.Equal(t, 3, pick(weights, 3.0000001))
+}
@@ -0,0 +1,506 @@
+package player
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/solver"
+	"github.com/barrettj12/500/util"
+
+	c "github.com/barrettj12/collections"
+)
+
+// ISMCTSPlayer is a computer player which uses information-set Monte Carlo
+// tree search. Rather than solving each possible deal separately (like
+// PIMCPlayer), it builds a single search tree over its own information set,
+// choosing a new random deal consistent with what it knows on each
+// iteration. This avoids "strategy fusion": assuming it can play differently
+// depending on hidden cards it can't actually see.
+//
+// Bids and discards are chosen by simulating the hand for each candidate,
+// with a bandit search over the candidates.
+type ISMCTSPlayer struct {
+	HeuristicPlayer
+
+	// Iterations is the number of iterations per decision. If zero,
+	// DefaultIterations is used.
+	Iterations int
+	// TimeBudget limits the time spent on each decision. The search stops
+	// once it is used up. If zero, there is no limit.
+	TimeBudget time.Duration
+	// Exploration is the UCB exploration constant. If zero,
+	// DefaultExploration is used.
+	Exploration float64
+	// Rand is the source of randomness. If nil, the global source is used.
+	// Decisions are reproducible with a seeded source and no TimeBudget.
+	Rand *rand.Rand
+}
+
+const (
+	// DefaultIterations is the number of iterations used by an ISMCTSPlayer
+	// by default.
+	DefaultIterations = 2000
+	// DefaultExploration is the UCB exploration constant used by an
+	// ISMCTSPlayer by default.
+	DefaultExploration = 0.7
+)
+
+// ISMCTSPlayer implements Player and Paced.
+var _ Player = &ISMCTSPlayer{}
+var _ Paced = &ISMCTSPlayer{}
+
+// budget calls iterate until the iteration or time budget is used up, or
+// the decision is interrupted.
+func (p *ISMCTSPlayer) budget(r *rand.Rand, iterate func(r *rand.Rand)) {
+	n := p.Iterations
+	if n == 0 {
+		n = DefaultIterations
+	}
+	var deadline time.Time
+	if p.TimeBudget > 0 {
+		deadline = time.Now().Add(p.TimeBudget)
+	}
+	for i := 0; i < n; i++ {
+		if i > 0 && (p.Interrupted() || !deadline.IsZero() && time.Now().After(deadline)) {
+			return
+		}
+		iterate(r)
+	}
+}
+
+// newRand returns a source of randomness for a decision.
+func (p *ISMCTSPlayer) newRand() *rand.Rand {
+	return rand.New(rand.NewSource(util.Int63(p.Rand)))
+}
+
+func (p *ISMCTSPlayer) exploration() float64 {
+	if p.Exploration == 0 {
+		return DefaultExploration
+	}
+	return p.Exploration
+}
+
+func (p *ISMCTSPlayer) Bid() game.Bid {
+	p.Pause()
+	p.explain("bid")
+	high := 0
+	if p.HighBid != nil {
+		high = p.HighBid.Value()
+	}
+	partnerHigh := p.HighBidder == partner(p.Seat)
+
+	// Candidates are bids up to a trick above the heuristic estimate
+	w := p.weights()
+	var candidates []game.Bid
+	for _, b := range game.AllBids() {
+		if b.Value() <= high || (partnerHigh && !sameDenomination(b, p.HighBid)) {
+			continue
+		}
+		switch b := b.(type) {
+		case game.SuitBid, game.NoTrumpsBid:
+			if maxTricks(EstimateTricks(p.Hand, w, b)+1) >= bidTricks(b) {
+				candidates = append(candidates, b)
+			}
+		case game.MisereBid:
+			if MisereRisk(p.Hand, b.Open) <= w.MisereRisk+1 {
+				candidates = append(candidates, b)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		p.rule("the hand isn't worth a bid")
+		p.expl.Choice = encodeBid(game.Pass{})
+		return game.Pass{}
+	}
+
+	// Simulate winning the contract with each bid. Passing scores 0.
+	unseen := p.unseen()
+	bandit := newBandit(len(candidates), p.exploration())
+	p.budget(p.newRand(), func(r *rand.Rand) {
+		i := bandit.choose()
+		bid := candidates[i]
+		hands, kitty := dealUnseen(r, unseen, p.Seat, p.Hand, 3)
+		hand := hands[p.Seat]
+		hand.Append(*kitty...)
+		discards := PlanDiscard(hand, bid)
+		hands[p.Seat] = hand.Filter(func(_ int, cd card.Card) bool { return !discards.Contains(cd) })
+
+		st := solver.NewState(solver.Position{Bid: bid, Contractor: p.Seat, Hands: hands, Leader: p.Seat})
+		playout(r, st, bid, p.Seat)
+		score := game.Score(result(bid, st.ContractorTricks()), p.Seat)
+		// Scale the score difference to roughly [0, 1]
+		bandit.update(i, 0.5+float64(score[p.Seat%2]-score[(p.Seat+1)%2])/2000)
+	})
+
+	p.expl.Candidates = append(p.expl.Candidates, Candidate{Action: encodeBid(game.Pass{}), Score: 0.5})
+	for i, b := range candidates {
+		p.expl.Candidates = append(p.expl.Candidates, Candidate{encodeBid(b), bandit.mean(i), bandit.visits[i]})
+	}
+	sortCandidates(p.expl.Candidates)
+	p.expl.Samples = bandit.total
+
+	var bid game.Bid = game.Pass{}
+	if best := bandit.best(); bandit.mean(best) <= 0.5 {
+		p.rule("no bid scored well in simulated games")
+	} else {
+		p.rule("scored best in simulated games")
+		bid = candidates[best]
+	}
+	p.expl.Choice = encodeBid(bid)
+	return bid
+}
+
+func (p *ISMCTSPlayer) Discard() *c.List[card.Card] {
+	p.Pause()
+	p.explain("discard")
+	// Candidates are the planned discard, and any three of the six weakest
+	// cards (or strongest in misère)
+	candidates := []*c.List[card.Card]{PlanDiscard(p.Hand, p.Contract)}
+	weakest := p.Hand.Copy()
+	_, misere := p.Contract.(game.MisereBid)
+	sort.SliceStable(*weakest, func(i, j int) bool {
+		a, b := p.strength((*weakest)[i]), p.strength((*weakest)[j])
+		if misere {
+			return a > b
+		}
+		return a < b
+	})
+	w := *weakest
+	for i := 0; i < 6; i++ {
+		for j := i + 1; j < 6; j++ {
+			for k := j + 1; k < 6; k++ {
+				candidates = append(candidates, c.AsList([]card.Card{w[i], w[j], w[k]}))
+			}
+		}
+	}
+
+	unseen := p.unseen()
+	bandit := newBandit(len(candidates), p.exploration())
+	p.budget(p.newRand(), func(r *rand.Rand) {
+		i := bandit.choose()
+		discards := candidates[i]
+		hands, _ := dealUnseen(r, unseen, p.Seat, p.Hand.Filter(func(_ int, cd card.Card) bool {
+			return !discards.Contains(cd)
+		}), 0)
+		st := solver.NewState(solver.Position{Bid: p.Contract, Contractor: p.Seat, Hands: hands, Leader: p.Seat})
+		playout(r, st, p.Contract, p.Seat)
+		bandit.update(i, reward(p.Contract, st.ContractorTricks(), true))
+	})
+
+	for i, d := range candidates {
+		p.expl.Candidates = append(p.expl.Candidates, Candidate{encodeCards(d), bandit.mean(i), bandit.visits[i]})
+	}
+	sortCandidates(p.expl.Candidates)
+	p.expl.Samples = bandit.total
+
+	p.Discards = candidates[bandit.best()]
+	p.rule("did best in simulated games")
+	p.expl.Choice = encodeCards(p.Discards)
+	return p.Discards
+}
+
+// unseen returns the cards not in the player's hand.
+func (p *ISMCTSPlayer) unseen() []card.Card {
+	var unseen []card.Card
+	for _, cd := range *game.GetDeck() {
+		if !p.Hand.Contains(cd) {
+			unseen = append(unseen, cd)
+		}
+	}
+	return unseen
+}
+
+// dealUnseen deals the unseen cards at random: 10 cards to each other
+// player, and the rest (up to kittySize) to the kitty.
+func dealUnseen(r *rand.Rand, unseen []card.Card, seat int, hand *c.List[card.Card], kittySize int) (hands [4]*c.List[card.Card], kitty *c.List[card.Card]) {
+	cards := c.AsList(append([]card.Card(nil), unseen...))
+	util.Shuffle(r, cards)
+	n := 0
+	for i := range hands {
+		if i == seat {
+			hands[i] = hand.Copy()
+			continue
+		}
+		hands[i] = util.E(cards.CopyPart(n, n+10))
+		n += 10
+	}
+	return hands, util.E(cards.CopyPart(n, n+kittySize))
+}
+
+func (p *ISMCTSPlayer) Play(trick *c.List[game.PlayInfo], validPlays *c.List[int]) int {
+	p.Pause()
+	p.explain("play")
+	if validPlays.Size() == 1 {
+		p.rule("the only valid card")
+		return p.chose(util.E(validPlays.Get(0)))
+	}
+
+	pos := solver.Position{
+		Bid:        p.Contract,
+		Contractor: p.Contractor,
+		Leader:     p.Leader,
+		Trick:      append([]game.PlayInfo(nil), p.Trick...),
+	}
+	wonSoFar := p.TeamTricks(p.Contractor)
+	root := &node{}
+	explore := p.exploration()
+
+	p.budget(p.newRand(), func(r *rand.Rand) {
+		pos := pos
+		pos.Hands = p.Inference.Sample(r)
+		st := solver.NewState(pos)
+
+		// Select and expand
+		n := root
+		for !st.Done() {
+			moves := st.Moves()
+			untried := n.update(moves)
+			if len(untried) > 0 {
+				m := untried[r.Intn(len(untried))]
+				n = n.add(m, st.ToMove())
+				st.Play(m)
+				break
+			}
+			n = n.selectChild(moves, explore)
+			st.Play(n.move)
+		}
+
+		playout(r, st, p.Contract, p.Contractor)
+
+		// Backpropagate
+		tricks := wonSoFar + st.ContractorTricks()
+		for ; n != root; n = n.parent {
+			n.visits++
+			n.reward += reward(p.Contract, tricks, n.player%2 == p.Contractor%2)
+		}
+		root.visits++
+	})
+
+	// Play the most visited card
+	best := p.defaultPlay(validPlays)
+	bestVisits := -1
+	for _, i := range *validPlays {
+		for _, ch := range root.children {
+			if ch.move == p.card(i) {
+				p.expl.Candidates = append(p.expl.Candidates, Candidate{encodeCard(ch.move), ch.reward / float64(ch.visits), ch.visits})
+				if ch.visits > bestVisits {
+					best, bestVisits = i, ch.visits
+				}
+			}
+		}
+	}
+	sortCandidates(p.expl.Candidates)
+	p.expl.Samples = root.visits
+	p.rule(fmt.Sprintf("did best in %d simulated games", root.visits))
+	return p.chose(best)
+}
+
+// node is a node in the ISMCTS search tree.
+type node struct {
+	move   card.Card
+	player int // the player who made the move
+	parent *node
+
+	children []*node
+	visits   int
+	// avail counts the iterations where the move was legal
+	avail  int
+	reward float64
+}
+
+// update counts the legal children as available, and returns the legal
+// moves which have no child yet.
+func (n *node) update(moves []card.Card) []card.Card {
+	var untried []card.Card
+	for _, m := range moves {
+		found := false
+		for _, ch := range n.children {
+			if ch.move == m {
+				ch.avail++
+				found = true
+				break
+			}
+		}
+		if !found {
+			untried = append(untried, m)
+		}
+	}
+	return untried
+}
+
+func (n *node) add(m card.Card, player int) *node {
+	ch := &node{move: m, player: player, parent: n, avail: 1}
+	n.children = append(n.children, ch)
+	return ch
+}
+
+// selectChild picks the legal child with the highest UCB score.
+func (n *node) selectChild(moves []card.Card, c float64) *node {
+	var best *node
+	bestScore := math.Inf(-1)
+	for _, ch := range n.children {
+		legal := false
+		for _, m := range moves {
+			if ch.move == m {
+				legal = true
+				break
+			}
+		}
+		if !legal {
+			continue
+		}
+		score := ch.reward/float64(ch.visits) + c*math.Sqrt(math.Log(float64(ch.avail))/float64(ch.visits))
+		if score > bestScore {
+			best, bestScore = ch, score
+		}
+	}
+	return best
+}
+
+// playout plays the hand to the end with a simple randomised policy.
+func playout(r *rand.Rand, st *solver.State, bid game.Bid, contractor int) {
+	_, misere := bid.(game.MisereBid)
+	for !st.Done() {
+		st.Play(playoutMove(r, st, misere && st.ToMove() == contractor))
+	}
+}
+
+// playoutMove picks a card: usually the cheapest card which wins the trick
+// for the player's side (or the lowest card), and sometimes a random card.
+// The misère contractor prefers the highest card which doesn't win.
+func playoutMove(r *rand.Rand, st *solver.State, misereContractor bool) card.Card {
+	moves := st.Moves()
+	if len(moves) == 1 || r.Intn(4) == 0 {
+		return moves[r.Intn(len(moves))]
+	}
+
+	var winning, losing []card.Card
+	for _, m := range moves {
+		if st.Beats(m) {
+			winning = append(winning, m)
+		} else {
+			losing = append(losing, m)
+		}
+	}
+	lowest := func(cards []card.Card) card.Card {
+		best := cards[0]
+		for _, cd := range cards {
+			if st.Rank(cd) < st.Rank(best) {
+				best = cd
+			}
+		}
+		return best
+	}
+	highest := func(cards []card.Card) card.Card {
+		best := cards[0]
+		for _, cd := range cards {
+			if st.Rank(cd) > st.Rank(best) {
+				best = cd
+			}
+		}
+		return best
+	}
+
+	if misereContractor {
+		if len(losing) > 0 {
+			return highest(losing)
+		}
+		return highest(moves)
+	}
+	if w := st.Winning(); (w >= 0 && st.SameSide(w, st.ToMove())) || len(winning) == 0 {
+		return lowest(moves)
+	}
+	return lowest(winning)
+}
+
+// result returns the result of the hand for the contractor's total tricks.
+func result(bid game.Bid, tricks int) game.HandResult {
+	if bid.Won(tricks) {
+		return game.BidWon{Bid: bid, Tricks: tricks}
+	}
+	return game.BidLost{Bid: bid, Tricks: tricks}
+}
+
+// reward scores the outcome of a hand in [0, 1], mostly for making or
+// defeating the contract, with a little for each trick.
+func reward(bid game.Bid, tricks int, contractorSide bool) float64 {
+	made := 0.0
+	if bid.Won(tricks) {
+		made = 1
+	}
+	frac := float64(tricks) / 10
+	if _, ok := bid.(game.MisereBid); ok {
+		frac = 1 - frac
+	}
+	r := 0.8*made + 0.2*frac
+	if !contractorSide {
+		r = 1 - r
+	}
+	return r
+}
+
+// bidTricks returns the number of tricks in a suit or no trumps bid.
+func bidTricks(b game.Bid) int {
+	switch b := b.(type) {
+	case game.SuitBid:
+		return b.Tricks
+	case game.NoTrumpsBid:
+		return b.Tricks
+	}
+	return 0
+}
+
+// bandit chooses between a fixed set of options using UCB1.
+type bandit struct {
+	visits  []int
+	rewards []float64
+	total   int
+	c       float64
+}
+
+func newBandit(n int, c float64) *bandit {
+	return &bandit{visits: make([]int, n), rewards: make([]float64, n), c: c}
+}
+
+func (b *bandit) choose() int {
+	best, bestScore := 0, math.Inf(-1)
+	for i, v := range b.visits {
+		if v == 0 {
+			return i
+		}
+		score := b.mean(i) + b.c*math.Sqrt(math.Log(float64(b.total))/float64(v))
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+func (b *bandit) update(i int, reward float64) {
+	b.visits[i]++
+	b.rewards[i] += reward
+	b.total++
+}
+
+func (b *bandit) mean(i int) float64 {
+	if b.visits[i] == 0 {
+		return 0
+	}
+	return b.rewards[i] / float64(b.visits[i])
+}
+
+// best returns the most visited option.
+func (b *bandit) best() int {
+	best := 0
+	for i, v := range b.visits {
+		if v > b.visits[best] {
+			best = i
+		}
+	}
+	return best
+}
@@ -0,0 +1,41 @@
+package player
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/stretchr/testify/assert"
+
+	c "github.com/barrettj12/collections"
+)
+
+func TestISMCTSReproducible(t *testing.T) {
+	newPlayer := func(seed int64) *ISMCTSPlayer {
+		p := &ISMCTSPlayer{Iterations: 200, Rand: rand.New(rand.NewSource(seed))}
+		p.NotifyPlayerNum(0)
+		p.NotifyHand(hand(
+			card.JokerCard, card.Card{card.Jack, card.Hearts}, card.Card{card.Jack, card.Diamonds},
+			card.Card{card.Ace, card.Hearts}, card.Card{9, card.Hearts}, card.Card{7, card.Hearts},
+			card.Card{card.Ace, card.Spades}, card.Card{5, card.Spades},
+			card.Card{6, card.Clubs}, card.Card{8, card.Diamonds},
+		))
+		return p
+	}
+
+	// A strong hand bids hearts
+	bid := newPlayer(1).Bid()
+	assert.Equal(t, bid, newPlayer(1).Bid())
+	if assert.IsType(t, game.SuitBid{}, bid) {
+		assert.Equal(t, card.Hearts, bid.(game.SuitBid).TrumpSuit)
+	}
+
+	// Card play is reproducible with the same seed
+	play := func(seed int64) int {
+		p := newPlayer(seed)
+		p.NotifyBidWinner(0, game.SuitBid{7, card.Hearts})
+		return p.Play(c.NewList[game.PlayInfo](0), c.AsList([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}))
+	}
+	assert.Equal(t, play(2), play(2))
+}
@@ -0,0 +1,147 @@
+package player
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/util"
+
+	c "github.com/barrettj12/collections"
+)
+
+// Level is a named difficulty level for computer players.
+type Level struct {
+	Name        string
+	Description string
+	// ErrorRate is the chance of playing a random valid card instead of the
+	// bot's choice.
+	ErrorRate float64
+
+	newBot func(r *rand.Rand, w *Weights) Player
+}
+
+// Levels are the available difficulty levels, from easiest to hardest.
+var Levels = []Level{{
+	Name:        "random",
+	Description: "never bids, and plays random cards",
+	newBot:      func(r *rand.Rand, _ *Weights) Player { return &RandomPlayer{Rand: r} },
+}, {
+	Name:        "easy",
+	Description: "simple heuristics, with frequent mistakes",
+	ErrorRate:   0.3,
+	newBot:      func(_ *rand.Rand, w *Weights) Player { return &HeuristicPlayer{Weights: w} },
+}, {
+	Name:        "medium",
+	Description: "simple heuristics, with occasional mistakes",
+	ErrorRate:   0.1,
+	newBot:      func(_ *rand.Rand, w *Weights) Player { return &HeuristicPlayer{Weights: w} },
+}, {
+	Name:        "hard",
+	Description: "bids from simulation tables, and plays by solving possible deals",
+	newBot: func(r *rand.Rand, w *Weights) Player {
+		h := HeuristicPlayer{Weights: w, Table: DefaultBidTable()}
+		return &PIMCPlayer{HeuristicPlayer: h, Samples: 20, TimeBudget: 2 * time.Second, Rand: r}
+	},
+}, {
+	Name:        "expert",
+	Description: "searches the game tree over possible deals",
+	newBot: func(r *rand.Rand, w *Weights) Player {
+		return &ISMCTSPlayer{HeuristicPlayer: HeuristicPlayer{Weights: w}, Iterations: 4000, TimeBudget: 3 * time.Second, Rand: r}
+	},
+}}
+
+// LevelNames returns the names of the difficulty levels.
+func LevelNames() []string {
+	names := make([]string, len(Levels))
+	for i, l := range Levels {
+		names[i] = l.Name
+	}
+	return names
+}
+
+// NewBot returns a computer player for the named difficulty level. If r is
+// nil, the global source of randomness is used. If w is nil, bots which
+// use weights use DefaultWeights.
+func NewBot(level string, r *rand.Rand, w *Weights) (Player, error) {
+	for _, l := range Levels {
+		if l.Name == level {
+			return l.New(r, w), nil
+		}
+	}
+	return nil, fmt.Errorf("unknown bot level %q (want one of %s)",
+		level, strings.Join(LevelNames(), ", "))
+}
+
+// New returns a computer player for this level.
+func (l Level) New(r *rand.Rand, w *Weights) Player {
+	p := l.newBot(r, w)
+	if l.ErrorRate > 0 {
+		p = &erringPlayer{Player: p, rate: l.ErrorRate, rand: r}
+	}
+	return p
+}
+
+// erringPlayer wraps a Player, sometimes playing a random valid card instead
+// of the one it chooses.
+type erringPlayer struct {
+	Player
+	rate float64
+	rand *rand.Rand
+
+	hand    *c.List[card.Card]
+	mistake string // the random card, if the last play was one
+}
+
+func (p *erringPlayer) SetInterrupt(interrupt <-chan struct{}) {
+	if i, ok := p.Player.(Interruptible); ok {
+		i.SetInterrupt(interrupt)
+	}
+}
+
+func (p *erringPlayer) SetView(v game.View) {
+	if vr, ok := p.Player.(ViewReceiver); ok {
+		vr.SetView(v)
+	}
+}
+
+func (p *erringPlayer) NotifyHand(hand *c.List[card.Card]) {
+	p.hand = hand
+	p.Player.NotifyHand(hand)
+}
+
+func (p *erringPlayer) SetPacing(pc Pacing) {
+	if pp, ok := p.Player.(Paced); ok {
+		pp.SetPacing(pc)
+	}
+}
+
+func (p *erringPlayer) Play(trick *c.List[game.PlayInfo], validPlays *c.List[int]) int {
+	// Ask the wrapped player anyway, so it pauses as usual
+	choice := p.Player.Play(trick, validPlays)
+	p.mistake = ""
+	if float64(util.Intn(p.rand, 1000)) < p.rate*1000 {
+		i := util.E(validPlays.Get(util.Intn(p.rand, validPlays.Size())))
+		if p.hand != nil {
+			p.mistake = encodeCard(util.E(p.hand.Get(i)))
+		}
+		return i
+	}
+	return choice
+}
+
+func (p *erringPlayer) Explain() *Explanation {
+	ex, ok := p.Player.(Explainer)
+	if !ok {
+		return nil
+	}
+	e := ex.Explain()
+	if e != nil && e.Kind == "play" && p.mistake != "" && p.mistake != e.Choice {
+		e.Rules = append(e.Rules, "made a deliberate mistake: played a random card instead")
+		e.Choice = p.mistake
+	}
+	return e
+}
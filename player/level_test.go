@@ -0,0 +1,20 @@
+package player
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBot(t *testing.T) {
+	for _, name := range LevelNames() {
+		p, err := NewBot(name, rand.New(rand.NewSource(1)), nil)
+		assert.NoError(t, err)
+		_, paced := p.(Paced)
+		assert.True(t, paced, name)
+	}
+
+	_, err := NewBot("grandmaster", nil, nil)
+	assert.ErrorContains(t, err, `unknown bot level "grandmaster"`)
+}
@@ -0,0 +1,58 @@
+package player
+
+import "time"
+
+// Pause is a point in the game where a delay can be added, so that people
+// can follow what is happening.
+type Pause int
+
+const (
+	// PauseAutoPlay is before the controller plays a player's only valid
+	// card for them.
+	PauseAutoPlay Pause = iota
+	// PauseBotDecision is before a computer player makes a decision.
+	PauseBotDecision
+	// PauseBeforePrompt is before a human player is asked to play a card.
+	PauseBeforePrompt
+)
+
+// Pacing decides how long to pause at each point in the game. The same
+// controller can then drive both interactive games and fast simulations.
+type Pacing interface {
+	Delay(Pause) time.Duration
+}
+
+// Schedule is a Pacing with a fixed delay for each Pause. Pauses not in the
+// schedule have no delay.
+type Schedule map[Pause]time.Duration
+
+func (s Schedule) Delay(p Pause) time.Duration {
+	return s[p]
+}
+
+var (
+	// HumanPacing pauses long enough for people to follow the game.
+	HumanPacing Pacing = Schedule{
+		PauseAutoPlay:     SLEEP,
+		PauseBotDecision:  SLEEP,
+		PauseBeforePrompt: SLEEP,
+	}
+	// Instant never pauses, for headless games and simulations.
+	Instant Pacing = Schedule{}
+)
+
+// Paced is an optional interface for a Player which pauses during the game.
+// The controller tells the player which Pacing to use at the start of the
+// game.
+type Paced interface {
+	SetPacing(Pacing)
+}
+
+// Sleep pauses for the delay given by the pacing. A nil Pacing doesn't pause.
+func Sleep(pc Pacing, p Pause) {
+	if pc != nil {
+		time.Sleep(pc.Delay(p))
+	}
+}
+
+const SLEEP = 500 * time.Millisecond
@@ -0,0 +1,140 @@
+package player
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/solver"
+	"github.com/barrettj12/500/util"
+
+	c "github.com/barrettj12/collections"
+)
+
+// PIMCPlayer is a computer player which plays cards using perfect-information
+// Monte Carlo sampling. For each decision, it deals the unseen cards at
+// random (consistent with what it has inferred), solves each deal double-dummy,
+// and plays the card with the best average outcome.
+//
+// It bids and discards like a HeuristicPlayer.
+type PIMCPlayer struct {
+	HeuristicPlayer
+
+	// Samples is the number of deals to solve for each decision. If zero,
+	// DefaultSamples is used.
+	Samples int
+	// TimeBudget limits the time spent on each decision. No more deals are
+	// solved once it is used up. If zero, there is no limit.
+	TimeBudget time.Duration
+	// Workers is the number of goroutines solving deals. If zero, one per
+	// CPU is used.
+	Workers int
+	// Rand is the source of randomness. If nil, the global source is used.
+	Rand *rand.Rand
+}
+
+// DefaultSamples is the number of deals solved by a PIMCPlayer by default.
+const DefaultSamples = 20
+
+// PIMCPlayer implements Player and Paced.
+var _ Player = &PIMCPlayer{}
+var _ Paced = &PIMCPlayer{}
+
+func (p *PIMCPlayer) Play(trick *c.List[game.PlayInfo], validPlays *c.List[int]) int {
+	p.Pause()
+	p.explain("play")
+	if validPlays.Size() == 1 {
+		p.rule("the only valid card")
+		return p.chose(util.E(validPlays.Get(0)))
+	}
+
+	samples := p.Samples
+	if samples == 0 {
+		samples = DefaultSamples
+	}
+	workers := p.Workers
+	if workers == 0 {
+		workers = runtime.NumCPU()
+	}
+	var deadline time.Time
+	if p.TimeBudget > 0 {
+		deadline = time.Now().Add(p.TimeBudget)
+	}
+
+	// Seeds are chosen up front, so the decision is reproducible
+	seeds := make(chan int64, samples)
+	for i := 0; i < samples; i++ {
+		seeds <- util.Int63(p.Rand)
+	}
+	close(seeds)
+
+	pos := solver.Position{
+		Bid:        p.Contract,
+		Contractor: p.Contractor,
+		Leader:     p.Leader,
+		Trick:      append([]game.PlayInfo(nil), p.Trick...),
+	}
+
+	var (
+		mu     sync.Mutex
+		totals = map[card.Card]int{}
+		solved int32
+		wg     sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for seed := range seeds {
+				timeUp := p.Interrupted() || !deadline.IsZero() && time.Now().After(deadline)
+				if timeUp && atomic.LoadInt32(&solved) > 0 {
+					return
+				}
+				pos := pos
+				pos.Hands = p.Inference.Sample(rand.New(rand.NewSource(seed)))
+				res := solver.SolveMoves(pos)
+
+				mu.Lock()
+				for cd, tricks := range res {
+					totals[cd] += tricks
+				}
+				mu.Unlock()
+				atomic.AddInt32(&solved, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Maximise the contractor's tricks, unless we are on the other side (or
+	// it's misère, but not both)
+	_, misere := p.Contract.(game.MisereBid)
+	sign := 1
+	if (p.Seat%2 == p.Contractor%2) == misere {
+		sign = -1
+	}
+
+	// Ties are broken in favour of the heuristic choice
+	best := p.defaultPlay(validPlays)
+	for _, i := range *validPlays {
+		if sign*totals[p.card(i)] > sign*totals[p.card(best)] {
+			best = i
+		}
+	}
+
+	// Candidates are scored by the average tricks, negated if we want fewer
+	for _, i := range *validPlays {
+		cd := p.card(i)
+		p.expl.Candidates = append(p.expl.Candidates, Candidate{
+			Action: encodeCard(cd), Score: float64(sign*totals[cd]) / float64(solved), Samples: int(solved),
+		})
+	}
+	sortCandidates(p.expl.Candidates)
+	p.expl.Samples = int(solved)
+	p.rule(fmt.Sprintf("best on average over %d possible deals", solved))
+	return p.chose(best)
+}
@@ -2,11 +2,13 @@ package player
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
@@ -30,13 +32,21 @@ type Player interface {
 	NotifyHand(*c.List[card.Card])
 	NotifyBid(player int, bid game.Bid)
 	NotifyBidWinner(player int, bid game.Bid)
+	// NotifyKitty is sent only to the contractor, with the kitty cards they
+	// have picked up.
+	NotifyKitty(kitty *c.List[card.Card])
+	// NotifyKittyTaken is sent to all other players when the contractor
+	// picks up the kitty.
+	NotifyKittyTaken(player int)
 	NotifyPlay(player int, card card.Card)
 	NotifyTrickWinner(player int)
 	NotifyHandResult(res game.HandResult)
 
 	// Requests
 	Bid() game.Bid
-	Drop3() *c.Set[int]
+	// Discard asks the contractor for the three cards they would like to
+	// discard, after picking up the kitty. The cards must be in their hand.
+	Discard() *c.List[card.Card]
 	// Play asks the player to play a card on the given trick.
 	// The returned response must be an element of validPlays.
 	Play(trick *c.List[game.PlayInfo], validPlays *c.List[int]) int
@@ -51,44 +61,107 @@ type HumanPlayer struct {
 	Hand  *c.List[card.Card]
 	Table [4]card.Card
 	valid *c.List[int]
+	// HotSeat should be set when several HumanPlayers share the terminal.
+	HotSeat *HotSeat
+	// Advisor, if set, suggests a decision when the user types "?" at a
+	// prompt. It is passed every event the HumanPlayer receives.
+	Advisor Advisor
+	// Debug, if set, is shown below the board, to show why the bots made
+	// their decisions.
+	Debug *DebugPane
+
+	seat    int
+	pending []string // messages not yet shown to the user (see HotSeat)
+	clock   *[4]time.Duration
+	running int
+	pacing  Pacing
 
 	bid    game.Bid
 	bidder int
+
+	// takeback is set when the user asks to take back their last bid or card
+	takeback bool
+	// replaying is set while the hand is replayed after a takeback
+	replaying bool
+	// hints counts the hints the user has asked for
+	hints int
+	// interrupt is closed if the current decision is abandoned
+	interrupt <-chan struct{}
+	// view is the public view of the game sent with the current request
+	view game.View
 }
 
-// HumanPlayer implements Player.
-var _ Player = &HumanPlayer{}
+// An Advisor is a computer player which can explain its decisions, so that it
+// can give hints to a HumanPlayer.
+type Advisor interface {
+	Player
+	// Reason explains the last decision in a few words, e.g. "draw trumps".
+	Reason() string
+}
 
-func (p *HumanPlayer) NotifyPlayerNum(int) {}
+// HumanPlayer implements Player, Takebacker and Rewinder.
+var _ Player = &HumanPlayer{}
+var _ Takebacker = &HumanPlayer{}
+var _ Rewinder = &HumanPlayer{}
+var _ ClockWatcher = &HumanPlayer{}
+var _ Paced = &HumanPlayer{}
+var _ Interruptible = &HumanPlayer{}
+var _ ViewReceiver = &HumanPlayer{}
+
+func (p *HumanPlayer) NotifyPlayerNum(n int) {
+	p.seat = n
+	p.advise(func(a Advisor) { a.NotifyPlayerNum(n) })
+}
 
 func (p *HumanPlayer) NotifyHand(hand *c.List[card.Card]) {
 	p.Hand = hand
+	p.advise(func(a Advisor) { a.NotifyHand(hand) })
 	p.redrawBoard()
 }
 
 func (p *HumanPlayer) NotifyBid(player int, b game.Bid) {
+	p.advise(func(a Advisor) { a.NotifyBid(player, b) })
 	if (b == game.Pass{}) {
-		fmt.Printf("%s passed\n", p.PlayerName(player))
+		p.printf("%s passed\n", p.PlayerName(player))
 	} else {
-		fmt.Printf("%s bid %s\n", p.PlayerName(player), b)
+		p.printf("%s bid %s\n", p.PlayerName(player), b)
 	}
 }
 
 func (p *HumanPlayer) NotifyBidWinner(player int, bid game.Bid) {
 	p.bid = bid
-	fmt.Printf("%s won the bidding with %s\n", p.PlayerName(player), bid)
-	pressToContinue()
+	p.bidder = player
+	p.advise(func(a Advisor) { a.NotifyBidWinner(player, bid) })
+	p.printf("%s won the bidding with %s\n", p.PlayerName(player), bid)
+	p.pressToContinue()
+}
+
+func (p *HumanPlayer) NotifyKitty(kitty *c.List[card.Card]) {
+	p.advise(func(a Advisor) { a.NotifyKitty(kitty) })
+	str := ""
+	for _, cd := range *kitty {
+		str += cd.String() + " "
+	}
+	p.printf("You picked up the kitty: %s\n", str)
+	p.pressToContinue()
+}
+
+func (p *HumanPlayer) NotifyKittyTaken(player int) {
+	p.advise(func(a Advisor) { a.NotifyKittyTaken(player) })
+	p.printf("%s picked up the kitty\n", p.PlayerName(player))
 }
 
 func (p *HumanPlayer) NotifyPlay(player int, card card.Card) {
 	p.Table[player] = card
+	p.advise(func(a Advisor) { a.NotifyPlay(player, card) })
 	p.redrawBoard()
 	// fmt.Printf("%s played %s\n", p.PlayerName(player), card)
 }
 
 func (p *HumanPlayer) NotifyTrickWinner(player int) {
-	fmt.Printf("%s won the trick\n", p.PlayerName(player))
-	pressToContinue()
+	p.advise(func(a Advisor) { a.NotifyTrickWinner(player) })
+	p.printf("%s won the trick\n", p.PlayerName(player))
+	p.pressToContinue()
 	p.clearTable()
 	p.redrawBoard()
 }
@@ -100,12 +173,104 @@ func (p *HumanPlayer) clearTable() {
 }
 
 func (p *HumanPlayer) NotifyHandResult(res game.HandResult) {
-	fmt.Println(res.Info())
+	p.advise(func(a Advisor) { a.NotifyHandResult(res) })
+	p.printf("%s\n", res.Info())
+	if p.Advisor != nil {
+		p.printf("Hints used: %d\n", p.hints)
+	}
+}
+
+// advise passes an event on to the advisor, if there is one.
+func (p *HumanPlayer) advise(f func(a Advisor)) {
+	if p.Advisor != nil {
+		f(p.Advisor)
+	}
+}
+
+// hint shows the advisor's suggestion, described by suggest, and the reason
+// for it. It returns errReprompt, so that the user is prompted again.
+func (p *HumanPlayer) hint(suggest func() string) error {
+	if p.Advisor == nil {
+		return fmt.Errorf("no hints in this game")
+	}
+	p.hints++
+	fmt.Printf("Hint: %s — %s\n", suggest(), p.Advisor.Reason())
+	return errReprompt
+}
+
+// withHint adds the hint command to a prompt, if there is an advisor.
+func (p *HumanPlayer) withHint(pr string) string {
+	if p.Advisor == nil {
+		return pr
+	}
+	return strings.TrimSuffix(pr, ": ") + " (? for a hint): "
+}
+
+func (p *HumanPlayer) NotifyTakeback(player int) {
+	p.printf("%s took back their last move\n", p.PlayerName(player))
+	p.replaying = true
+}
+
+func (p *HumanPlayer) NotifyReplayDone() {
+	p.replaying = false
+	p.redrawBoard()
+}
+
+func (p *HumanPlayer) NotifyTakebackRefused() {
+	p.printf("%s\n", util.Red("Takeback refused"))
+}
+
+func (p *HumanPlayer) SetPacing(pc Pacing) {
+	p.pacing = pc
+}
+
+// canTakeBack says whether the house rules allow the user to take back their
+// last bid or card.
+func (p *HumanPlayer) canTakeBack() bool {
+	return !p.view.Rules.NoTakebacks
+}
+
+// SetView stores the public view of the game for the next decision.
+func (p *HumanPlayer) SetView(v game.View) {
+	p.view = v
+}
+
+// SetInterrupt makes the prompts for the next decision give up when
+// interrupt is closed, e.g. when the user runs out of time.
+func (p *HumanPlayer) SetInterrupt(interrupt <-chan struct{}) {
+	p.interrupt = interrupt
+}
+
+func (p *HumanPlayer) NotifyClock(remaining [4]time.Duration, running int) {
+	p.clock = &remaining
+	p.running = running
+}
+
+func (p *HumanPlayer) WantsTakeback() bool {
+	t := p.takeback
+	p.takeback = false
+	return t
+}
+
+func (p *HumanPlayer) AllowTakeback(player int) bool {
+	p.takeSeat()
+	return prompt(p.interrupt, fmt.Sprintf("%s wants to take back their last move. Allow? [y/n]: ", p.PlayerName(player)),
+		func(s string) (bool, error) {
+			switch s {
+			case "y":
+				return true, nil
+			case "n":
+				return false, nil
+			default:
+				return false, fmt.Errorf(`expected "y" or "n", received %q`, s)
+			}
+		})
 }
 
 func (p *HumanPlayer) Bid() game.Bid {
+	p.takeSeat()
 	promptTricks := func() int {
-		return prompt("Tricks [6-10]: ", func(s string) (int, error) {
+		return prompt(p.interrupt, "Tricks [6-10]: ", func(s string) (int, error) {
 			i, err := strconv.Atoi(s)
 			if err != nil {
 				return 0, err
@@ -117,7 +282,7 @@ func (p *HumanPlayer) Bid() game.Bid {
 		})
 	}
 	promptOpenMis := func() bool {
-		return prompt("Open [o] or closed [c]? ", func(s string) (bool, error) {
+		return prompt(p.interrupt, "Open [o] or closed [c]? ", func(s string) (bool, error) {
 			switch s {
 			case "o":
 				return true, nil
@@ -129,8 +294,19 @@ func (p *HumanPlayer) Bid() game.Bid {
 		})
 	}
 
-	return prompt("Enter bid [s/c/d/h/n/m/p]: ", func(s string) (game.Bid, error) {
+	pr := "Enter bid [s/c/d/h/n/m/p]: "
+	if p.canTakeBack() {
+		pr = "Enter bid [s/c/d/h/n/m/p], or u to undo: "
+	}
+	return prompt(p.interrupt, p.withHint(pr), func(s string) (game.Bid, error) {
 		switch s {
+		case "?":
+			return nil, p.hint(func() string {
+				if bid := p.Advisor.Bid(); (bid != game.Pass{}) {
+					return fmt.Sprintf("bid %s", bid)
+				}
+				return "pass"
+			})
 		case "s":
 			return game.SuitBid{TrumpSuit: card.Spades, Tricks: promptTricks()}, nil
 		case "c":
@@ -145,14 +321,32 @@ func (p *HumanPlayer) Bid() game.Bid {
 			return game.MisereBid{Open: promptOpenMis()}, nil
 		case "p":
 			return game.Pass{}, nil
+		case "u":
+			if p.canTakeBack() {
+				p.takeback = true
+				return nil, nil
+			}
+			fallthrough
 		default:
 			return nil, fmt.Errorf("unknown bid %q", s)
 		}
 	})
 }
 
-func (p *HumanPlayer) Drop3() *c.Set[int] {
-	return prompt("Cards to dump [x,y,z]: ", func(s string) (*c.Set[int], error) {
+func (p *HumanPlayer) Discard() *c.List[card.Card] {
+	p.takeSeat()
+	return prompt(p.interrupt, p.withHint("Cards to discard [x,y,z]: "), func(s string) (*c.List[card.Card], error) {
+		if s == "?" {
+			return nil, p.hint(func() string {
+				var nums, cards []string
+				for _, cd := range *p.Advisor.Discard() {
+					i, _ := p.Hand.Find(cd)
+					nums = append(nums, strconv.Itoa(i))
+					cards = append(cards, cd.String())
+				}
+				return fmt.Sprintf("discard %s (%s)", strings.Join(nums, ","), strings.Join(cards, " "))
+			})
+		}
 		nums := strings.Split(s, ",")
 		if len(nums) != 3 {
 			return nil, fmt.Errorf("expected 3 nums, received %d", len(nums))
@@ -164,7 +358,7 @@ func (p *HumanPlayer) Drop3() *c.Set[int] {
 			if err != nil {
 				return nil, err
 			}
-			if n < 0 || n > 12 {
+			if n < 0 || n >= p.Hand.Size() {
 				return nil, fmt.Errorf("%d is out of range", n)
 			}
 			ints.Add(n)
@@ -175,18 +369,33 @@ func (p *HumanPlayer) Drop3() *c.Set[int] {
 			return nil, fmt.Errorf("repeated numbers in %s", s)
 		}
 
-		return ints, nil
+		return p.Hand.Filter(func(i int, _ card.Card) bool { return ints.Contains(i) }), nil
 	})
 }
 
 func (p *HumanPlayer) Play(trick *c.List[game.PlayInfo], validPlays *c.List[int]) int {
-	time.Sleep(SLEEP)
+	Sleep(p.pacing, PauseBeforePrompt)
+	p.takeSeat()
 	// Show valid cards
 	p.valid = validPlays
 	defer func() { p.valid = nil }()
 	p.redrawBoard()
 
-	return prompt("play card: ", func(s string) (int, error) {
+	pr := "play card: "
+	if p.canTakeBack() {
+		pr = "play card (or u to undo): "
+	}
+	return prompt(p.interrupt, p.withHint(pr), func(s string) (int, error) {
+		if s == "?" {
+			return 0, p.hint(func() string {
+				i := p.Advisor.Play(trick, validPlays)
+				return fmt.Sprintf("play %d (%s)", i, util.E(p.Hand.Get(i)))
+			})
+		}
+		if s == "u" && p.canTakeBack() {
+			p.takeback = true
+			return -1, nil
+		}
 		j, err := strconv.Atoi(s)
 		if err != nil {
 			return 0, err
@@ -199,8 +408,14 @@ func (p *HumanPlayer) Play(trick *c.List[game.PlayInfo], validPlays *c.List[int]
 }
 
 func (p *HumanPlayer) JokerSuit() card.Suit {
-	return prompt("Choose suit for Joker [s/c/d/h]: ", func(s string) (card.Suit, error) {
+	p.takeSeat()
+	return prompt(p.interrupt, p.withHint("Choose suit for Joker [s/c/d/h]: "), func(s string) (card.Suit, error) {
 		switch s {
+		case "?":
+			return "", p.hint(func() string {
+				suit := p.Advisor.JokerSuit()
+				return fmt.Sprintf("%s %s", suit.Symbol(true), strings.ToLower(string(suit)))
+			})
 		case "s":
 			return card.Spades, nil
 		case "c":
@@ -217,18 +432,25 @@ func (p *HumanPlayer) JokerSuit() card.Suit {
 
 // Prompt the user for input.
 // A function can be provided to validate and transform the given input.
-func prompt[T any](pr string, f func(string) (T, error)) T {
-	s := bufio.NewScanner(os.Stdin)
+// If interrupt is closed before the user answers, prompt returns the zero
+// value.
+func prompt[T any](interrupt <-chan struct{}, pr string, f func(string) (T, error)) T {
 	var res T
 
 	for {
 		fmt.Print(pr)
-		s.Scan()
-		if err := s.Err(); err != nil {
-			panic(err)
+		var input string
+		select {
+		case line, ok := <-stdinLines():
+			if !ok && stdinErr != nil {
+				panic(stdinErr)
+			}
+			input = line
+		case <-interrupt:
+			fmt.Println()
+			return res
 		}
 
-		input := s.Text()
 		var err error
 		res, err = f(input)
 		if err == nil {
@@ -236,40 +458,113 @@ func prompt[T any](pr string, f func(string) (T, error)) T {
 		}
 
 		// Invalid input
-		fmt.Println(util.Red(fmt.Sprintf("INVALID: %s", err)))
+		if err != errReprompt {
+			fmt.Println(util.Red(fmt.Sprintf("INVALID: %s", err)))
+		}
 	}
 
 	return res
 }
 
+// stdin is read by a single goroutine, so that a prompt which is
+// interrupted doesn't leave a read behind to take the next line.
+var (
+	stdinOnce sync.Once
+	stdinCh   chan string
+	stdinErr  error // set before stdinCh is closed
+)
+
+// stdinLines returns a channel of the lines read from stdin. It is closed at
+// the end of the input.
+func stdinLines() <-chan string {
+	stdinOnce.Do(func() {
+		stdinCh = make(chan string)
+		go func() {
+			s := bufio.NewScanner(os.Stdin)
+			for s.Scan() {
+				stdinCh <- s.Text()
+			}
+			stdinErr = s.Err()
+			close(stdinCh)
+		}()
+	})
+	return stdinCh
+}
+
+// ReadLine reads a line from stdin, without the trailing newline. It returns
+// "" at the end of the input. Code which reads stdin as well as HumanPlayers
+// must use ReadLine, as their prompts read ahead.
+func ReadLine() string {
+	return <-stdinLines()
+}
+
+// errReprompt is returned by a prompt's function to prompt again without
+// printing an error, e.g. after showing a hint.
+var errReprompt = errors.New("prompt again")
+
 func pressToContinue() {
 	fmt.Println("[press enter to continue]")
-	prompt("", func(s string) (int, error) { return 0, nil })
+	prompt(nil, "", func(s string) (int, error) { return 0, nil })
+}
+
+// While the hand is being replayed after a takeback, HumanPlayer doesn't
+// print anything or wait for the user. In hot-seat mode, only the active
+// player prints to the terminal - messages for other players are saved until
+// it is their turn.
+
+func (p *HumanPlayer) printf(format string, a ...any) {
+	if p.replaying {
+		return
+	}
+	if !p.isActive() {
+		p.pending = append(p.pending, fmt.Sprintf(format, a...))
+		return
+	}
+	fmt.Printf(format, a...)
+}
+
+func (p *HumanPlayer) pressToContinue() {
+	if !p.replaying && p.isActive() {
+		pressToContinue()
+	}
 }
 
 func (p *HumanPlayer) redrawBoard() {
+	if p.replaying || !p.isActive() {
+		return
+	}
 	screen.Clear()
 
 	tmpl := util.E(template.New("test").Parse(`
-Bid: {{.PrintBid}}
+Bid: {{.PrintBid}}{{with .PrintClock}}
+{{.}}{{end}}
 
-        {{.PlayerName 2}}
-        {{.FmtTable 2}}
-  {{.PlayerName 1}}         {{.PlayerName 3}}
-  {{.FmtTable 1}}         {{.FmtTable 3}}
-        {{.PlayerName 0}}
-        {{.FmtTable 0}}
+        {{.PlayerName (.SeatAt 2)}}
+        {{.FmtTable (.SeatAt 2)}}
+  {{.PlayerName (.SeatAt 1)}}         {{.PlayerName (.SeatAt 3)}}
+  {{.FmtTable (.SeatAt 1)}}         {{.FmtTable (.SeatAt 3)}}
+        {{.PlayerName (.SeatAt 0)}}
+        {{.FmtTable (.SeatAt 0)}}
 
 {{.PrintHand}}
-
+{{with .PrintDebug}}
+{{.}}
+{{end}}
 `[1:]))
 
 	util.E0(tmpl.Execute(screen.Writer(), p))
 	screen.Update()
 }
 
+// PlayerName returns the name of the given player, relative to this player.
 func (p *HumanPlayer) PlayerName(player int) string {
-	return []string{"You", "Op1", "Pnr", "Op2"}[player]
+	return []string{"You", "Op1", "Pnr", "Op2"}[(player-p.seat+4)%4]
+}
+
+// SeatAt returns the player sitting at the given position on the board,
+// counting clockwise from this player (at position 0).
+func (p *HumanPlayer) SeatAt(pos int) int {
+	return (p.seat + pos) % 4
 }
 
 func (p *HumanPlayer) PrintBid() string {
@@ -279,6 +574,35 @@ func (p *HumanPlayer) PrintBid() string {
 	return fmt.Sprintf("%s by %s", p.bid, p.PlayerName(p.bidder))
 }
 
+// PrintClock returns the time remaining for each player, or "" if the game
+// doesn't have time controls.
+func (p *HumanPlayer) PrintClock() string {
+	if p.clock == nil {
+		return ""
+	}
+
+	str := "Clock:"
+	for pos := 0; pos < 4; pos++ {
+		seat := p.SeatAt(pos)
+		t := p.clock[seat].Round(time.Second)
+		entry := fmt.Sprintf(" %s %d:%02d", p.PlayerName(seat), int(t.Minutes()), int(t.Seconds())%60)
+		if seat == p.running {
+			entry = util.Red(entry)
+		}
+		str += entry
+	}
+	return str
+}
+
+// PrintDebug returns the bots' latest explanations, or "" if the debug pane
+// isn't shown.
+func (p *HumanPlayer) PrintDebug() string {
+	if p.Debug == nil {
+		return ""
+	}
+	return p.Debug.String()
+}
+
 // Returns player's card suitable for printing.
 // Always has 3 characters.
 func FmtCard(c card.Card, grey bool) string {
@@ -326,40 +650,36 @@ func (p *HumanPlayer) PrintHand() string {
 
 // Plays a random (valid) card each round.
 type RandomPlayer struct {
-	Delay time.Duration
+	BotBase
+
+	// Rand is the source of randomness. If nil, the global source is used.
+	Rand *rand.Rand
 }
 
-// Random implements Player.
+// Random implements Player and Paced.
 var _ Player = &RandomPlayer{}
-
-func (p *RandomPlayer) NotifyPlayerNum(int)                      {}
-func (p *RandomPlayer) NotifyHand(*c.List[card.Card])            {}
-func (p *RandomPlayer) NotifyBid(player int, bid game.Bid)       {}
-func (p *RandomPlayer) NotifyBidWinner(player int, bid game.Bid) {}
-func (p *RandomPlayer) NotifyPlay(player int, card card.Card)    {}
-func (p *RandomPlayer) NotifyTrickWinner(player int)             {}
-func (p *RandomPlayer) NotifyHandResult(res game.HandResult)     {}
+var _ Paced = &RandomPlayer{}
 
 func (p *RandomPlayer) Bid() game.Bid {
-	time.Sleep(p.Delay)
+	p.Pause()
 	// Random player doesn't bid
 	return game.Pass{}
 }
 
-func (p *RandomPlayer) Drop3() *c.Set[int] {
-	// Random player never wins bid, so we don't need to implement
-	panic("RandomPlayer.Drop3 unimplemented")
+func (p *RandomPlayer) Discard() *c.List[card.Card] {
+	p.Pause()
+	hand := p.Hand.Copy()
+	util.Shuffle(p.Rand, hand)
+	return util.E(hand.CopyPart(0, 3))
 }
 
 func (p *RandomPlayer) Play(trick *c.List[game.PlayInfo], validPlays *c.List[int]) int {
-	time.Sleep(p.Delay)
-	n := rand.Intn(validPlays.Size())
+	p.Pause()
+	n := util.Intn(p.Rand, validPlays.Size())
 	return util.E(validPlays.Get(n))
 }
 
 func (p *RandomPlayer) JokerSuit() card.Suit {
-	time.Sleep(p.Delay)
-	return []card.Suit{card.Spades, card.Clubs, card.Diamonds, card.Hearts}[rand.Intn(4)]
+	p.Pause()
+	return []card.Suit{card.Spades, card.Clubs, card.Diamonds, card.Hearts}[util.Intn(p.Rand, 4)]
 }
-
-const SLEEP = 500 * time.Millisecond
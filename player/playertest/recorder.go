@@ -0,0 +1,161 @@
+package playertest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/player"
+	"github.com/stretchr/testify/assert"
+
+	c "github.com/barrettj12/collections"
+)
+
+// Call is a call to one of a player's Notify methods.
+type Call struct {
+	Method string // e.g. "NotifyBid"
+	Args   []any
+}
+
+func (cl Call) String() string {
+	args := make([]string, len(cl.Args))
+	for i, a := range cl.Args {
+		args[i] = fmt.Sprint(a)
+	}
+	return fmt.Sprintf("%s(%s)", cl.Method, strings.Join(args, ", "))
+}
+
+// Recorder wraps a player, recording every call to its Notify methods in
+// order. Requests are passed on to the wrapped player.
+type Recorder struct {
+	player.Player
+	Calls []Call
+}
+
+// NewRecorder returns a Recorder wrapping the given player. If p is nil, a
+// Scripted player with no script is used.
+func NewRecorder(p player.Player) *Recorder {
+	if p == nil {
+		p = &Scripted{}
+	}
+	return &Recorder{Player: p}
+}
+
+func (r *Recorder) record(method string, args ...any) {
+	r.Calls = append(r.Calls, Call{method, args})
+}
+
+func (r *Recorder) NotifyPlayerNum(n int) {
+	r.record("NotifyPlayerNum", n)
+	r.Player.NotifyPlayerNum(n)
+}
+
+func (r *Recorder) NotifyHand(hand *c.List[card.Card]) {
+	// The controller changes the hand as it is played
+	r.record("NotifyHand", hand.Copy())
+	r.Player.NotifyHand(hand)
+}
+
+func (r *Recorder) NotifyBid(player int, bid game.Bid) {
+	r.record("NotifyBid", player, bid)
+	r.Player.NotifyBid(player, bid)
+}
+
+func (r *Recorder) NotifyBidWinner(player int, bid game.Bid) {
+	r.record("NotifyBidWinner", player, bid)
+	r.Player.NotifyBidWinner(player, bid)
+}
+
+func (r *Recorder) NotifyKitty(kitty *c.List[card.Card]) {
+	r.record("NotifyKitty", kitty.Copy())
+	r.Player.NotifyKitty(kitty)
+}
+
+func (r *Recorder) NotifyKittyTaken(player int) {
+	r.record("NotifyKittyTaken", player)
+	r.Player.NotifyKittyTaken(player)
+}
+
+func (r *Recorder) NotifyPlay(player int, cd card.Card) {
+	r.record("NotifyPlay", player, cd)
+	r.Player.NotifyPlay(player, cd)
+}
+
+func (r *Recorder) NotifyTrickWinner(player int) {
+	r.record("NotifyTrickWinner", player)
+	r.Player.NotifyTrickWinner(player)
+}
+
+func (r *Recorder) NotifyHandResult(res game.HandResult) {
+	r.record("NotifyHandResult", res)
+	r.Player.NotifyHandResult(res)
+}
+
+// Find returns the recorded calls to the given method.
+func (r *Recorder) Find(method string) []Call {
+	var calls []Call
+	for _, cl := range r.Calls {
+		if cl.Method == method {
+			calls = append(calls, cl)
+		}
+	}
+	return calls
+}
+
+// Args returns the arguments of each recorded call to the given method, in
+// order. It is useful for single-argument methods, e.g.
+//
+//	winners := r.Args("NotifyTrickWinner")
+func (r *Recorder) Args(method string) []any {
+	var args []any
+	for _, cl := range r.Find(method) {
+		args = append(args, cl.Args...)
+	}
+	return args
+}
+
+// AssertCalled asserts that the given method was called with the given
+// arguments.
+func (r *Recorder) AssertCalled(t assert.TestingT, method string, args ...any) bool {
+	want := Call{method, args}
+	for _, cl := range r.Calls {
+		if assert.ObjectsAreEqual(want, cl) {
+			return true
+		}
+	}
+	return assert.Fail(t, fmt.Sprintf("%s was not called", want), r.history())
+}
+
+// AssertNotCalled asserts that the given method was never called.
+func (r *Recorder) AssertNotCalled(t assert.TestingT, method string) bool {
+	if calls := r.Find(method); len(calls) > 0 {
+		return assert.Fail(t, fmt.Sprintf("unexpected call %s", calls[0]), r.history())
+	}
+	return true
+}
+
+// AssertCalledInOrder asserts that the given calls were made in the given
+// order. Other calls may come between them.
+func (r *Recorder) AssertCalledInOrder(t assert.TestingT, calls ...Call) bool {
+	next := 0
+	for _, cl := range r.Calls {
+		if next < len(calls) && assert.ObjectsAreEqual(calls[next], cl) {
+			next++
+		}
+	}
+	if next < len(calls) {
+		return assert.Fail(t, fmt.Sprintf("%s was not called after %d expected calls", calls[next], next), r.history())
+	}
+	return true
+}
+
+// history lists the recorded calls, for failure messages.
+func (r *Recorder) history() string {
+	var sb strings.Builder
+	sb.WriteString("calls:")
+	for _, cl := range r.Calls {
+		sb.WriteString("\n\t" + cl.String())
+	}
+	return sb.String()
+}
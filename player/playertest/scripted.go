@@ -0,0 +1,89 @@
+// Package playertest provides players for testing the controller and other
+// code which drives players.
+package playertest
+
+import (
+	"fmt"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/player"
+
+	c "github.com/barrettj12/collections"
+)
+
+// Scripted is a player which makes predetermined decisions.
+//
+// Bids are made in order, and it passes once they run out. Plays are given
+// by card, and once they run out it plays its first valid card. Playing a
+// card which isn't valid panics, as the controller would otherwise keep
+// asking for a valid play.
+type Scripted struct {
+	player.BotBase
+	Bids []game.Bid
+	// Discards are the cards to discard after picking up the kitty. If nil,
+	// the first three cards in the hand are discarded.
+	Discards []card.Card
+	Plays    []card.Card
+	// NamedSuit is the suit named when the Joker is led in no trumps or
+	// misère. If empty, Spades is named.
+	NamedSuit card.Suit
+
+	bids, plays int
+}
+
+// Scripted implements Player.
+var _ player.Player = &Scripted{}
+
+func (s *Scripted) Bid() game.Bid {
+	if s.bids >= len(s.Bids) {
+		return game.Pass{}
+	}
+	s.bids++
+	return s.Bids[s.bids-1]
+}
+
+func (s *Scripted) Discard() *c.List[card.Card] {
+	if s.Discards == nil {
+		return s.Hand.Filter(func(i int, _ card.Card) bool { return i < 3 })
+	}
+	return c.AsList(append([]card.Card(nil), s.Discards...))
+}
+
+func (s *Scripted) Play(trick *c.List[game.PlayInfo], validPlays *c.List[int]) int {
+	if s.plays >= len(s.Plays) {
+		return (*validPlays)[0]
+	}
+	cd := s.Plays[s.plays]
+	s.plays++
+	i, err := s.Hand.Find(cd)
+	if err != nil || !validPlays.Contains(i) {
+		panic(fmt.Sprintf("playertest: player %d can't play %s", s.Seat, cd))
+	}
+	return i
+}
+
+func (s *Scripted) JokerSuit() card.Suit {
+	if s.NamedSuit == card.NoSuit {
+		return card.Spades
+	}
+	return s.NamedSuit
+}
+
+// Deal returns a deck which deals the given hands and kitty, for use as
+// controller.Controller.Deck. It panics unless there are 10 cards in each
+// hand and 3 in the kitty.
+func Deal(hands [4][]card.Card, kitty []card.Card) *c.List[card.Card] {
+	deck := c.NewList[card.Card](43)
+	for i, h := range hands {
+		if len(h) != 10 {
+			panic(fmt.Sprintf("playertest: hand %d has %d cards", i, len(h)))
+		}
+		deck.Append(h...)
+	}
+	if len(kitty) != 3 {
+		panic(fmt.Sprintf("playertest: kitty has %d cards", len(kitty)))
+	}
+	deck.Append(kitty...)
+	return deck
+}
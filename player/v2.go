@@ -0,0 +1,429 @@
+package player
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+
+	c "github.com/barrettj12/collections"
+)
+
+// PlayerV2 is an event-based version of the Player interface. Rather than one
+// method per event or request, the controller sends typed Events and Requests,
+// so that new game features don't require changes to the interface (or to
+// every implementation of it).
+//
+// Existing Player implementations can be used as a PlayerV2 via Adapt.
+type PlayerV2 interface {
+	// Notify informs the player of an Event.
+	Notify(ctx context.Context, e Event) error
+	// Decide asks the player to respond to a Request. The concrete type of
+	// the Response depends on the Request (e.g. a BidRequest must be
+	// answered with a BidResponse).
+	Decide(ctx context.Context, r Request) (Response, error)
+}
+
+// Event is something that has happened in the game, which the controller
+// informs a player about.
+type Event interface {
+	event()
+}
+
+// PlayerNumEvent tells a player which seat they are in.
+type PlayerNumEvent struct {
+	Player int
+}
+
+// HandEvent tells a player the current contents of their hand.
+type HandEvent struct {
+	Hand *c.List[card.Card]
+}
+
+// BidEvent says that a player made a bid (or passed).
+type BidEvent struct {
+	Player int
+	Bid    game.Bid
+}
+
+// BidWinnerEvent says that a player won the bidding.
+type BidWinnerEvent struct {
+	Player int
+	Bid    game.Bid
+}
+
+// KittyEvent is sent only to the contractor, with the kitty cards they have
+// picked up.
+type KittyEvent struct {
+	Kitty *c.List[card.Card]
+}
+
+// KittyTakenEvent says that the contractor has picked up the kitty.
+type KittyTakenEvent struct {
+	Player int
+}
+
+// PlayEvent says that a player played a card.
+type PlayEvent struct {
+	Player int
+	Card   card.Card
+}
+
+// TrickWinnerEvent says that a player won the trick.
+type TrickWinnerEvent struct {
+	Player int
+}
+
+// HandResultEvent gives the outcome of the hand.
+type HandResultEvent struct {
+	Result game.HandResult
+}
+
+// TakebackEvent says that a player has taken back their last bid or card.
+// It is followed by a replay of the events in the hand up to that point,
+// and then a ReplayDoneEvent.
+type TakebackEvent struct {
+	Player int
+}
+
+// ReplayDoneEvent says that the replay after a TakebackEvent is finished.
+type ReplayDoneEvent struct{}
+
+// TakebackRefusedEvent is sent to a player when their takeback was refused,
+// either by the house rules or by an opponent.
+type TakebackRefusedEvent struct{}
+
+// ClockEvent gives the time remaining on each player's clock, when the game
+// has time controls. Running is the player whose clock is now running, or -1
+// if no clock is running.
+type ClockEvent struct {
+	Remaining [4]time.Duration
+	Running   int
+}
+
+// PacingEvent tells a player how long to pause at each point in the game.
+// It is sent at the start of the game.
+type PacingEvent struct {
+	Pacing Pacing
+}
+
+func (PlayerNumEvent) event()       {}
+func (HandEvent) event()            {}
+func (BidEvent) event()             {}
+func (BidWinnerEvent) event()       {}
+func (KittyEvent) event()           {}
+func (KittyTakenEvent) event()      {}
+func (PlayEvent) event()            {}
+func (TrickWinnerEvent) event()     {}
+func (HandResultEvent) event()      {}
+func (TakebackEvent) event()        {}
+func (ReplayDoneEvent) event()      {}
+func (TakebackRefusedEvent) event() {}
+func (ClockEvent) event()           {}
+func (PacingEvent) event()          {}
+
+// Request asks a player for a decision. Each Request carries a View of the
+// public game state at the time the request is made.
+type Request interface {
+	request()
+}
+
+// BidRequest asks a player to bid or pass. It is answered with a BidResponse.
+type BidRequest struct {
+	View game.View
+}
+
+// DiscardRequest asks the contractor for three cards to discard after
+// picking up the kitty. It is answered with a DiscardResponse.
+type DiscardRequest struct {
+	View game.View
+}
+
+// PlayRequest asks a player to play a card on the given trick. It is answered
+// with a PlayResponse, whose index must be an element of ValidPlays.
+type PlayRequest struct {
+	Trick      *c.List[game.PlayInfo]
+	ValidPlays *c.List[int]
+	View       game.View
+}
+
+// JokerSuitRequest asks for a suit for the Joker when it is led in no trumps
+// or misere. It is answered with a JokerSuitResponse.
+type JokerSuitRequest struct {
+	View game.View
+}
+
+// AllowTakebackRequest asks whether the player allows an opponent to take
+// back their last bid or card. It is answered with an AllowTakebackResponse.
+type AllowTakebackRequest struct {
+	Player int
+	View   game.View
+}
+
+func (BidRequest) request()           {}
+func (DiscardRequest) request()       {}
+func (PlayRequest) request()          {}
+func (JokerSuitRequest) request()     {}
+func (AllowTakebackRequest) request() {}
+
+// Response is a player's answer to a Request.
+type Response interface {
+	response()
+}
+
+// BidResponse answers a BidRequest.
+type BidResponse struct {
+	Bid game.Bid
+}
+
+// DiscardResponse answers a DiscardRequest.
+type DiscardResponse struct {
+	Cards *c.List[card.Card]
+}
+
+// PlayResponse answers a PlayRequest, giving the index in the player's hand
+// of the card to play.
+type PlayResponse struct {
+	Index int
+}
+
+// JokerSuitResponse answers a JokerSuitRequest.
+type JokerSuitResponse struct {
+	Suit card.Suit
+}
+
+// TakebackResponse can be given in answer to a BidRequest or PlayRequest, to
+// take back the player's last bid or card instead.
+type TakebackResponse struct{}
+
+// AllowTakebackResponse answers an AllowTakebackRequest.
+type AllowTakebackResponse struct {
+	Allow bool
+}
+
+func (BidResponse) response()           {}
+func (DiscardResponse) response()       {}
+func (PlayResponse) response()          {}
+func (JokerSuitResponse) response()     {}
+func (TakebackResponse) response()      {}
+func (AllowTakebackResponse) response() {}
+
+// Takebacker is an optional interface for a Player which may want to take
+// back its last bid or card.
+type Takebacker interface {
+	// WantsTakeback is called after Bid or Play returns. If it returns true,
+	// the returned bid or card is ignored, and the player's last bid or card
+	// is taken back instead.
+	WantsTakeback() bool
+	// AllowTakeback asks whether the player allows the given opponent to take
+	// back their last bid or card.
+	AllowTakeback(player int) bool
+	// NotifyTakebackRefused says that the player's takeback was refused.
+	NotifyTakebackRefused()
+}
+
+// Rewinder is an optional interface for a Player which needs to know when
+// the hand is rewound after a takeback.
+type Rewinder interface {
+	// NotifyTakeback says that the given player took back their last bid or
+	// card. It is followed by a replay of the hand's events up to that point.
+	NotifyTakeback(player int)
+	// NotifyReplayDone says that the replay is finished.
+	NotifyReplayDone()
+}
+
+// ClockWatcher is an optional interface for a Player which wants to know the
+// time remaining on each player's clock.
+type ClockWatcher interface {
+	NotifyClock(remaining [4]time.Duration, running int)
+}
+
+// Interruptible is an optional interface for a Player whose decisions can be
+// abandoned part way through, e.g. when it runs out of time.
+type Interruptible interface {
+	// SetInterrupt is called before each decision with a channel which is
+	// closed if the decision is abandoned. The Player should then return
+	// from the decision as soon as it can. Its answer is discarded.
+	SetInterrupt(interrupt <-chan struct{})
+}
+
+// ViewReceiver is an optional interface for a Player which wants the public
+// view of the game sent with each request.
+type ViewReceiver interface {
+	// SetView is called before each decision with the request's view.
+	SetView(v game.View)
+}
+
+// Adapt wraps a Player so that it can be used as a PlayerV2.
+// Events which have no equivalent in the Player interface are ignored, unless
+// the Player implements the relevant optional interface (e.g. Rewinder).
+// Players which don't implement Takebacker allow all takebacks.
+//
+// If the context passed to Decide is cancelled (e.g. because the player ran
+// out of time), Decide returns straight away, and the Player is interrupted
+// if it is Interruptible. Its eventual answer is discarded, and the Player
+// isn't sent anything else until it has answered.
+func Adapt(p Player) PlayerV2 {
+	return &adapter{Player: p}
+}
+
+// adapter converts a Player into a PlayerV2.
+type adapter struct {
+	Player
+	// abandoned receives the answer to a decision which was abandoned
+	abandoned <-chan answer
+}
+
+// answer is a Player's answer to a request.
+type answer struct {
+	resp Response
+	err  error
+}
+
+// wait waits for the Player to answer an abandoned decision, if any.
+func (a *adapter) wait() {
+	if a.abandoned != nil {
+		<-a.abandoned
+		a.abandoned = nil
+	}
+}
+
+func (a *adapter) Notify(ctx context.Context, e Event) error {
+	a.wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	switch e := e.(type) {
+	case PlayerNumEvent:
+		a.NotifyPlayerNum(e.Player)
+	case HandEvent:
+		a.NotifyHand(e.Hand)
+	case BidEvent:
+		a.NotifyBid(e.Player, e.Bid)
+	case BidWinnerEvent:
+		a.NotifyBidWinner(e.Player, e.Bid)
+	case KittyEvent:
+		a.NotifyKitty(e.Kitty)
+	case KittyTakenEvent:
+		a.NotifyKittyTaken(e.Player)
+	case PlayEvent:
+		a.NotifyPlay(e.Player, e.Card)
+	case TrickWinnerEvent:
+		a.NotifyTrickWinner(e.Player)
+	case HandResultEvent:
+		a.NotifyHandResult(e.Result)
+	case TakebackEvent:
+		if r, ok := a.Player.(Rewinder); ok {
+			r.NotifyTakeback(e.Player)
+		}
+	case ReplayDoneEvent:
+		if r, ok := a.Player.(Rewinder); ok {
+			r.NotifyReplayDone()
+		}
+	case TakebackRefusedEvent:
+		if t, ok := a.Player.(Takebacker); ok {
+			t.NotifyTakebackRefused()
+		}
+	case ClockEvent:
+		if w, ok := a.Player.(ClockWatcher); ok {
+			w.NotifyClock(e.Remaining, e.Running)
+		}
+	case PacingEvent:
+		if pc, ok := a.Player.(Paced); ok {
+			pc.SetPacing(e.Pacing)
+		}
+	}
+	return nil
+}
+
+func (a *adapter) Decide(ctx context.Context, r Request) (Response, error) {
+	a.wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if i, ok := a.Player.(Interruptible); ok {
+		i.SetInterrupt(ctx.Done())
+	}
+	if v, ok := a.Player.(ViewReceiver); ok {
+		v.SetView(requestView(r))
+	}
+	if ctx.Done() == nil {
+		// Context can't be cancelled
+		return a.decide(r)
+	}
+
+	ch := make(chan answer, 1)
+	go func() {
+		resp, err := a.decide(r)
+		ch <- answer{resp, err}
+	}()
+
+	select {
+	case res := <-ch:
+		return res.resp, res.err
+	case <-ctx.Done():
+		a.abandoned = ch
+		return nil, ctx.Err()
+	}
+}
+
+func (a *adapter) decide(r Request) (Response, error) {
+	switch r := r.(type) {
+	case BidRequest:
+		bid := a.Bid()
+		if a.wantsTakeback() {
+			return TakebackResponse{}, nil
+		}
+		return BidResponse{bid}, nil
+	case DiscardRequest:
+		return DiscardResponse{a.Discard()}, nil
+	case PlayRequest:
+		n := a.Play(r.Trick, r.ValidPlays)
+		if a.wantsTakeback() {
+			return TakebackResponse{}, nil
+		}
+		return PlayResponse{n}, nil
+	case JokerSuitRequest:
+		return JokerSuitResponse{a.JokerSuit()}, nil
+	case AllowTakebackRequest:
+		allow := true
+		if t, ok := a.Player.(Takebacker); ok {
+			allow = t.AllowTakeback(r.Player)
+		}
+		return AllowTakebackResponse{allow}, nil
+	default:
+		return nil, fmt.Errorf("unsupported request %T", r)
+	}
+}
+
+// requestView returns the view sent with a request.
+func requestView(r Request) game.View {
+	switch r := r.(type) {
+	case BidRequest:
+		return r.View
+	case DiscardRequest:
+		return r.View
+	case PlayRequest:
+		return r.View
+	case JokerSuitRequest:
+		return r.View
+	case AllowTakebackRequest:
+		return r.View
+	}
+	return game.View{}
+}
+
+func (a *adapter) Explain() *Explanation {
+	if ex, ok := a.Player.(Explainer); ok {
+		return ex.Explain()
+	}
+	return nil
+}
+
+func (a *adapter) wantsTakeback() bool {
+	t, ok := a.Player.(Takebacker)
+	return ok && t.WantsTakeback()
+}
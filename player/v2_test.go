@@ -0,0 +1,86 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingPlayer bids once it is released, ignoring interrupts.
+type blockingPlayer struct {
+	RandomPlayer
+	release chan struct{}
+	calls   []string
+}
+
+func (p *blockingPlayer) Bid() game.Bid {
+	<-p.release
+	p.calls = append(p.calls, "Bid")
+	return game.Pass{}
+}
+
+func (p *blockingPlayer) NotifyBid(player int, bid game.Bid) {
+	p.calls = append(p.calls, "NotifyBid")
+}
+
+func TestAdaptAbandonedDecision(t *testing.T) {
+	bp := &blockingPlayer{release: make(chan struct{})}
+	a := Adapt(bp)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := a.Decide(ctx, BidRequest{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The next event waits for the abandoned bid
+	notified := make(chan error)
+	go func() { notified <- a.Notify(context.Background(), BidEvent{1, game.Pass{}}) }()
+	select {
+	case <-notified:
+		t.Fatal("Notify didn't wait for the abandoned decision")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(bp.release)
+	assert.NoError(t, <-notified)
+	assert.Equal(t, []string{"Bid", "NotifyBid"}, bp.calls)
+}
+
+func TestAdaptInterrupt(t *testing.T) {
+	// A bot pausing for a long time is interrupted
+	a := Adapt(&RandomPlayer{})
+	assert.NoError(t, a.Notify(context.Background(), PacingEvent{Schedule{PauseBotDecision: time.Minute}}))
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := a.Decide(ctx, BidRequest{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NoError(t, a.Notify(context.Background(), BidEvent{1, game.Pass{}}))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestAdaptView(t *testing.T) {
+	p := &RandomPlayer{}
+	a := Adapt(p)
+	assert.NoError(t, a.Notify(context.Background(), PlayerNumEvent{1}))
+	assert.NoError(t, a.Notify(context.Background(), HandEvent{hand(card.Card{4, card.Spades})}))
+
+	view := game.View{
+		Bids:       []game.BidInfo{{0, game.SuitBid{6, card.Spades}}},
+		Contractor: -1,
+		CardsLeft:  [4]int{10, 10, 10, 10},
+		Score:      [2]int{40, -100},
+	}
+	_, err := a.Decide(context.Background(), BidRequest{view})
+	assert.NoError(t, err)
+	assert.Equal(t, view, p.View)
+
+	view.Bids = append(view.Bids, game.BidInfo{1, game.Pass{}})
+	_, err = a.Decide(context.Background(), JokerSuitRequest{view})
+	assert.NoError(t, err)
+	assert.Equal(t, view, p.View)
+}
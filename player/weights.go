@@ -0,0 +1,29 @@
+package player
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// LoadWeights reads weights from a JSON file, as written by
+// SaveWeights. Weights missing from the file take their default values.
+func LoadWeights(path string) (*Weights, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	w := DefaultWeights
+	if err := json.Unmarshal(data, &w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// SaveWeights writes weights to a JSON file.
+func SaveWeights(path string, w Weights) error {
+	data, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,28 @@
+package player
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadWeights(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "weights.json")
+	w := DefaultWeights
+	w.Joker = 1.25
+	w.MisereRisk = 2
+	assert.NoError(t, SaveWeights(path, w))
+
+	loaded, err := LoadWeights(path)
+	assert.NoError(t, err)
+	assert.Equal(t, w, *loaded)
+
+	// Missing weights take their default values
+	assert.NoError(t, os.WriteFile(path, []byte(`{"Support": 1.5}`), 0644))
+	loaded, err = LoadWeights(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, loaded.Support)
+	assert.Equal(t, DefaultWeights.Joker, loaded.Joker)
+}
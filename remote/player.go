@@ -6,6 +6,8 @@ import (
 	"github.com/barrettj12/500/card"
 	"github.com/barrettj12/500/game"
 	main "github.com/barrettj12/500/player"
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 
@@ -20,6 +22,15 @@ type RemotePlayer struct {
 // RemotePlayer implements Player.
 var _ main.Player = &RemotePlayer{}
 
+// Dial connects to a remote player at the given address (host:port).
+func Dial(addr string) (*RemotePlayer, error) {
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	return &RemotePlayer{client: NewPlayerClient(conn)}, nil
+}
+
 func (p *RemotePlayer) NotifyPlayerNum(playerNum int) {
 	_, err := p.client.NotifyPlayerNum(
 		context.Background(),
@@ -36,8 +47,37 @@ func (p *RemotePlayer) NotifyHand(hand *c.List[card.Card]) {
 	panicIfNotNil(err)
 }
 
-func (p *RemotePlayer) NotifyBid(player int, bid game.Bid)       {}
-func (p *RemotePlayer) NotifyBidWinner(player int, bid game.Bid) {}
+func (p *RemotePlayer) NotifyBid(player int, bid game.Bid) {
+	_, err := p.client.NotifyBid(
+		context.Background(),
+		encodeBidInfo(player, bid),
+	)
+	panicIfNotNil(err)
+}
+
+func (p *RemotePlayer) NotifyBidWinner(player int, bid game.Bid) {
+	_, err := p.client.NotifyBidWinner(
+		context.Background(),
+		encodeBidInfo(player, bid),
+	)
+	panicIfNotNil(err)
+}
+
+func (p *RemotePlayer) NotifyKitty(kitty *c.List[card.Card]) {
+	_, err := p.client.NotifyKitty(
+		context.Background(),
+		encodeHand(kitty),
+	)
+	panicIfNotNil(err)
+}
+
+func (p *RemotePlayer) NotifyKittyTaken(player int) {
+	_, err := p.client.NotifyKittyTaken(
+		context.Background(),
+		&wrapperspb.Int32Value{Value: int32(player)},
+	)
+	panicIfNotNil(err)
+}
 
 func (p *RemotePlayer) NotifyPlay(player int, card card.Card) {
 	_, err := p.client.NotifyPlay(
@@ -58,17 +98,30 @@ func (p *RemotePlayer) NotifyTrickWinner(player int) {
 	panicIfNotNil(err)
 }
 
-func (p *RemotePlayer) NotifyHandResult(res game.HandResult) {}
+func (p *RemotePlayer) NotifyHandResult(res game.HandResult) {
+	_, err := p.client.NotifyHandResult(
+		context.Background(),
+		encodeHandResult(res),
+	)
+	panicIfNotNil(err)
+}
 
 func (p *RemotePlayer) Bid() game.Bid {
-	// TODO: implement properly
-	return game.Pass{}
+	resp, err := p.client.Bid(
+		context.Background(),
+		&emptypb.Empty{},
+	)
+	panicIfNotNil(err)
+	return decodeBid(resp)
 }
 
-func (p *RemotePlayer) Drop3() *c.Set[int] {
-	// Should not be called yet, as we don't have RemotePlayer bidding.
-	// TODO: implement properly
-	panic("RemotePlayer.Drop3 not implemented")
+func (p *RemotePlayer) Discard() *c.List[card.Card] {
+	resp, err := p.client.Discard(
+		context.Background(),
+		&emptypb.Empty{},
+	)
+	panicIfNotNil(err)
+	return decodeHand(resp)
 }
 
 func (p *RemotePlayer) Play(trick *c.List[game.PlayInfo], validPlays *c.List[int]) int {
@@ -84,8 +137,12 @@ func (p *RemotePlayer) Play(trick *c.List[game.PlayInfo], validPlays *c.List[int
 }
 
 func (p *RemotePlayer) JokerSuit() card.Suit {
-	// TODO: implement properly
-	panic("RemotePlayer.Drop3 not implemented")
+	resp, err := p.client.JokerSuit(
+		context.Background(),
+		&emptypb.Empty{},
+	)
+	panicIfNotNil(err)
+	return decodeSuit(resp.Value)
 }
 
 func panicIfNotNil(err error) {
@@ -104,6 +161,12 @@ type RemoteController struct {
 
 var _ PlayerServer = &RemoteController{}
 
+// NewRemoteController returns a server which passes the events and requests
+// it receives to the given player. It is registered with RegisterPlayerServer.
+func NewRemoteController(p main.Player) *RemoteController {
+	return &RemoteController{player: p}
+}
+
 func (c *RemoteController) NotifyPlayerNum(_ context.Context, n *wrapperspb.Int32Value) (*emptypb.Empty, error) {
 	c.player.NotifyPlayerNum(int(n.Value))
 	return nil, nil
@@ -114,6 +177,26 @@ func (c *RemoteController) NotifyHand(_ context.Context, h *Hand) (*emptypb.Empt
 	return nil, nil
 }
 
+func (c *RemoteController) NotifyBid(_ context.Context, bi *BidInfo) (*emptypb.Empty, error) {
+	c.player.NotifyBid(int(bi.Player), decodeBid(bi.Bid))
+	return nil, nil
+}
+
+func (c *RemoteController) NotifyBidWinner(_ context.Context, bi *BidInfo) (*emptypb.Empty, error) {
+	c.player.NotifyBidWinner(int(bi.Player), decodeBid(bi.Bid))
+	return nil, nil
+}
+
+func (c *RemoteController) NotifyKitty(_ context.Context, h *Hand) (*emptypb.Empty, error) {
+	c.player.NotifyKitty(decodeHand(h))
+	return nil, nil
+}
+
+func (c *RemoteController) NotifyKittyTaken(_ context.Context, n *wrapperspb.Int32Value) (*emptypb.Empty, error) {
+	c.player.NotifyKittyTaken(int(n.Value))
+	return nil, nil
+}
+
 func (c *RemoteController) NotifyPlay(_ context.Context, pi *PlayInfo) (*emptypb.Empty, error) {
 	c.player.NotifyPlay(
 		int(pi.Player),
@@ -127,6 +210,15 @@ func (c *RemoteController) NotifyTrickWinner(_ context.Context, winner *wrappers
 	return nil, nil
 }
 
+func (c *RemoteController) NotifyHandResult(_ context.Context, res *HandResult) (*emptypb.Empty, error) {
+	c.player.NotifyHandResult(decodeHandResult(res))
+	return nil, nil
+}
+
+func (c *RemoteController) Bid(_ context.Context, _ *emptypb.Empty) (*Bid, error) {
+	return encodeBid(c.player.Bid()), nil
+}
+
 func (c *RemoteController) Play(_ context.Context, req *PlayRequest) (*wrapperspb.Int32Value, error) {
 	n := c.player.Play(
 		decodeTrick(req.Trick),
@@ -134,3 +226,11 @@ func (c *RemoteController) Play(_ context.Context, req *PlayRequest) (*wrappersp
 	)
 	return &wrapperspb.Int32Value{Value: int32(n)}, nil
 }
+
+func (c *RemoteController) Discard(_ context.Context, _ *emptypb.Empty) (*Hand, error) {
+	return encodeHand(c.player.Discard()), nil
+}
+
+func (c *RemoteController) JokerSuit(_ context.Context, _ *emptypb.Empty) (*SuitValue, error) {
+	return &SuitValue{Value: encodeSuit(c.player.JokerSuit())}, nil
+}
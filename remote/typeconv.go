@@ -83,6 +83,83 @@ func decodeSuit(s Suit) card.Suit {
 	}
 }
 
+// encodeBidInfo converts a bid made by a player to a *BidInfo.
+func encodeBidInfo(player int, bid game.Bid) *BidInfo {
+	return &BidInfo{
+		Player: int32(player),
+		Bid:    encodeBid(bid),
+	}
+}
+
+// encodeBid converts a main.Bid to a *Bid. A nil bid is encoded as nil.
+func encodeBid(bid game.Bid) *Bid {
+	switch b := bid.(type) {
+	case nil:
+		return nil
+	case game.Pass:
+		return &Bid{Kind: BidKind_PASS}
+	case game.SuitBid:
+		return &Bid{Kind: BidKind_SUIT_BID, Tricks: int32(b.Tricks), TrumpSuit: encodeSuit(b.TrumpSuit)}
+	case game.NoTrumpsBid:
+		return &Bid{Kind: BidKind_NO_TRUMPS_BID, Tricks: int32(b.Tricks)}
+	case game.MisereBid:
+		return &Bid{Kind: BidKind_MISERE_BID, Open: b.Open}
+	default:
+		panic(fmt.Sprintf("unknown bid %T", bid))
+	}
+}
+
+// decodeBid converts a *Bid to a main.Bid.
+func decodeBid(b *Bid) game.Bid {
+	if b == nil {
+		return nil
+	}
+	switch b.Kind {
+	case BidKind_PASS:
+		return game.Pass{}
+	case BidKind_SUIT_BID:
+		return game.SuitBid{Tricks: int(b.Tricks), TrumpSuit: decodeSuit(b.TrumpSuit)}
+	case BidKind_NO_TRUMPS_BID:
+		return game.NoTrumpsBid{Tricks: int(b.Tricks)}
+	case BidKind_MISERE_BID:
+		return game.MisereBid{Open: b.Open}
+	default:
+		panic(fmt.Sprintf("unknown bid kind %v", b.Kind))
+	}
+}
+
+// encodeHandResult converts a main.HandResult to a *HandResult.
+func encodeHandResult(res game.HandResult) *HandResult {
+	switch r := res.(type) {
+	case game.Redeal:
+		return &HandResult{Kind: HandResultKind_REDEAL}
+	case game.BidWon:
+		return &HandResult{Kind: HandResultKind_BID_WON, Bid: encodeBid(r.Bid), Tricks: int32(r.Tricks)}
+	case game.BidLost:
+		return &HandResult{Kind: HandResultKind_BID_LOST, Bid: encodeBid(r.Bid), Tricks: int32(r.Tricks)}
+	case game.Forfeit:
+		return &HandResult{Kind: HandResultKind_FORFEIT, Bid: encodeBid(r.Bid), Player: int32(r.Player)}
+	default:
+		panic(fmt.Sprintf("unknown hand result %T", res))
+	}
+}
+
+// decodeHandResult converts a *HandResult to a main.HandResult.
+func decodeHandResult(r *HandResult) game.HandResult {
+	switch r.Kind {
+	case HandResultKind_REDEAL:
+		return game.Redeal{}
+	case HandResultKind_BID_WON:
+		return game.BidWon{Bid: decodeBid(r.Bid), Tricks: int(r.Tricks)}
+	case HandResultKind_BID_LOST:
+		return game.BidLost{Bid: decodeBid(r.Bid), Tricks: int(r.Tricks)}
+	case HandResultKind_FORFEIT:
+		return game.Forfeit{Player: int(r.Player), Bid: decodeBid(r.Bid)}
+	default:
+		panic(fmt.Sprintf("unknown hand result kind %v", r.Kind))
+	}
+}
+
 // encodeTrick converts a *c.List[main.PlayInfo] to a []*PlayInfo.
 func encodeTrick(list *c.List[game.PlayInfo]) []*PlayInfo {
 	return encodeList(list, encodePlayInfo)
@@ -0,0 +1,27 @@
+package remote
+
+import (
+	"testing"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBidRoundTrip(t *testing.T) {
+	for _, bid := range []game.Bid{
+		nil, game.Pass{}, game.SuitBid{7, card.Hearts}, game.NoTrumpsBid{Tricks: 8},
+		game.MisereBid{}, game.MisereBid{Open: true},
+	} {
+		assert.Equal(t, bid, decodeBid(encodeBid(bid)))
+	}
+}
+
+func TestHandResultRoundTrip(t *testing.T) {
+	for _, res := range []game.HandResult{
+		game.Redeal{}, game.BidWon{game.SuitBid{6, card.Spades}, 7}, game.BidLost{game.NoTrumpsBid{Tricks: 9}, 4},
+		game.Forfeit{Player: 2}, game.Forfeit{Player: 1, Bid: game.MisereBid{}},
+	} {
+		assert.Equal(t, res, decodeHandResult(encodeHandResult(res)))
+	}
+}
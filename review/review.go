@@ -0,0 +1,353 @@
+// Package review analyses a finished hand with the double-dummy solver. It
+// replays every card, comparing each one with the best card the player could
+// have played, and comments on whether the contract could have been made.
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/player"
+	"github.com/barrettj12/500/solver"
+
+	c "github.com/barrettj12/collections"
+)
+
+// Review is the analysis of a hand. Trick counts are the tricks taken by the
+// contractor's side, with perfect play by everyone from then on.
+type Review struct {
+	Bid        game.Bid
+	Contractor int
+	Result     game.HandResult
+
+	// Par is the number of tricks after the contractor's discard.
+	Par int
+	// BestDiscard is the best of the discards tried, and BestPar the number
+	// of tricks after it.
+	BestDiscard *c.List[card.Card]
+	BestPar     int
+
+	Plays []Play
+	Seats [4]Seat
+	// Comments are remarks on the contract as a whole.
+	Comments []string
+}
+
+// Play is the analysis of one card played.
+type Play struct {
+	Trick  int // counting from 0
+	Player int
+	Card   card.Card
+	// Tricks is the number of tricks after the card is played.
+	Tricks int
+	// Best is the card which was best for the player, and BestTricks the
+	// number of tricks after it. If several cards were best, the one played
+	// is given.
+	Best       card.Card
+	BestTricks int
+	// Lost is the number of tricks the card cost the player's side.
+	Lost int
+}
+
+// Seat totals the mistakes made by a player.
+type Seat struct {
+	Errors     int
+	TricksLost int
+}
+
+// discardCandidates is the number of weakest cards (strongest in misère)
+// which the alternative discards are chosen from.
+const discardCandidates = 6
+
+// Hand reviews a finished hand. Only hands which were played out can be
+// reviewed.
+func Hand(rec game.HandRecord) (*Review, error) {
+	switch rec.Result.(type) {
+	case game.BidWon, game.BidLost:
+	default:
+		return nil, fmt.Errorf("hand wasn't played out")
+	}
+	if rec.Hands[0] == nil || len(rec.Tricks) != 10 {
+		return nil, fmt.Errorf("hand record is incomplete")
+	}
+
+	r := &Review{Bid: rec.Bid, Contractor: rec.Contractor, Result: rec.Result}
+	_, misere := rec.Bid.(game.MisereBid)
+
+	// Hands after the contractor's discard
+	var hands [4]*c.List[card.Card]
+	for i := range hands {
+		hands[i] = rec.Hands[i].Copy()
+	}
+	picked := hands[rec.Contractor]
+	picked.Append(*rec.Kitty...)
+	without := func(discards *c.List[card.Card]) *c.List[card.Card] {
+		return picked.Filter(func(_ int, cd card.Card) bool { return !discards.Contains(cd) })
+	}
+	hands[rec.Contractor] = without(rec.Discards)
+
+	start := solver.Position{Bid: rec.Bid, Contractor: rec.Contractor, Hands: hands, Leader: rec.Contractor}
+	r.Par = solver.Solve(copyPosition(start))
+	r.BestDiscard, r.BestPar = rec.Discards, r.Par
+	for _, d := range discards(picked, rec.Bid, misere) {
+		pos := copyPosition(start)
+		pos.Hands[rec.Contractor] = without(d)
+		if par := solver.Solve(pos); better(par, r.BestPar, misere) {
+			r.BestDiscard, r.BestPar = d, par
+		}
+	}
+
+	// Replay the card play
+	pos := copyPosition(start)
+	won := 0 // by the contractor's side, in completed tricks
+	for t, trick := range rec.Tricks {
+		pos.Leader = trick.Leader
+		pos.Trick = nil
+		for _, pl := range *trick.Plays {
+			res := solver.SolveMoves(copyPosition(pos))
+			// Players want the contractor's side to take more tricks if they
+			// are on it, unless it's misère
+			wantMore := (pl.Player%2 == rec.Contractor%2) != misere
+			p := Play{Trick: t, Player: pl.Player, Card: pl.Card, Tricks: won + res[pl.Card], Best: pl.Card}
+			p.BestTricks = p.Tricks
+			for _, cd := range sortedCards(res) {
+				if tricks := won + res[cd]; better(tricks, p.BestTricks, !wantMore) {
+					p.Best, p.BestTricks = cd, tricks
+				}
+			}
+			p.Lost = p.BestTricks - p.Tricks
+			if p.Lost < 0 {
+				p.Lost = -p.Lost
+			}
+			if p.Lost > 0 {
+				r.Seats[pl.Player].Errors++
+				r.Seats[pl.Player].TricksLost += p.Lost
+			}
+			r.Plays = append(r.Plays, p)
+
+			pos.Hands[pl.Player] = pos.Hands[pl.Player].Filter(func(_ int, cd card.Card) bool { return cd != pl.Card })
+			pos.Trick = append(pos.Trick, pl)
+		}
+		if trick.Winner%2 == rec.Contractor%2 {
+			won++
+		}
+	}
+
+	r.comment(rec, misere)
+	return r, nil
+}
+
+// better says whether a is better than b for the contractor: more tricks,
+// or fewer in misère.
+func better(a, b int, misere bool) bool {
+	if misere {
+		return a < b
+	}
+	return a > b
+}
+
+// comment adds comments on the contract as a whole.
+func (r *Review) comment(rec game.HandRecord, misere bool) {
+	made := func(tricks int) bool {
+		if misere {
+			return tricks == 0
+		}
+		return rec.Bid.Won(tricks)
+	}
+
+	if made(r.Par) {
+		r.Comments = append(r.Comments, fmt.Sprintf("Makeable double-dummy (%d tricks)", r.Par))
+	} else {
+		r.Comments = append(r.Comments, fmt.Sprintf("Not makeable double-dummy (%d tricks)", r.Par))
+	}
+	if r.BestPar != r.Par {
+		kind := "A better discard"
+		if made(r.BestPar) && !made(r.Par) {
+			kind = "Makeable with a different discard"
+		}
+		r.Comments = append(r.Comments, fmt.Sprintf("%s: %s (%d tricks)", kind, plainCards(r.BestDiscard), r.BestPar))
+	}
+
+	_, won := rec.Result.(game.BidWon)
+	switch {
+	case made(r.Par) && !won:
+		r.Comments = append(r.Comments, "Went down in the play")
+	case !made(r.Par) && won:
+		r.Comments = append(r.Comments, "Made thanks to defensive mistakes")
+	}
+}
+
+// discards returns the alternative discards to try: the one PlanDiscard
+// picks, and every three of the weakest cards (strongest in misère).
+func discards(picked *c.List[card.Card], bid game.Bid, misere bool) []*c.List[card.Card] {
+	cards := picked.Copy()
+	sort.SliceStable(*cards, func(i, j int) bool {
+		a, b := strength(bid, (*cards)[i]), strength(bid, (*cards)[j])
+		if misere {
+			return a > b
+		}
+		return a < b
+	})
+	w := *cards
+	res := []*c.List[card.Card]{player.PlanDiscard(picked, bid)}
+	for i := 0; i < discardCandidates; i++ {
+		for j := i + 1; j < discardCandidates; j++ {
+			for k := j + 1; k < discardCandidates; k++ {
+				res = append(res, c.AsList([]card.Card{w[i], w[j], w[k]}))
+			}
+		}
+	}
+	return res
+}
+
+// strength ranks a card by how likely it is to win a trick.
+func strength(bid game.Bid, cd card.Card) int {
+	if cd == card.JokerCard {
+		return 100
+	}
+	order := bid.CardOrder(cd)
+	pos, _ := order.Find(cd)
+	return 100 - pos
+}
+
+func copyPosition(pos solver.Position) solver.Position {
+	for i, h := range pos.Hands {
+		if h != nil {
+			pos.Hands[i] = h.Copy()
+		}
+	}
+	pos.Trick = append([]game.PlayInfo(nil), pos.Trick...)
+	return pos
+}
+
+// sortedCards returns the cards in res, in a fixed order.
+func sortedCards(res map[card.Card]int) []card.Card {
+	cards := make([]card.Card, 0, len(res))
+	for cd := range res {
+		cards = append(cards, cd)
+	}
+	sort.Slice(cards, func(i, j int) bool {
+		if cards[i].Suit != cards[j].Suit {
+			return cards[i].Suit < cards[j].Suit
+		}
+		return cards[i].Rank < cards[j].Rank
+	})
+	return cards
+}
+
+// Write prints the review, naming the players with the given names. If
+// colour is false, no terminal colour codes are used, e.g. for saving the
+// review to a file.
+func (r *Review) Write(w io.Writer, names [4]string, colour bool) error {
+	cardStr := func(cd card.Card) string { return cd.Rank.String() + cd.Suit.Symbol(colour) }
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Contract: %s by %s, %s\n", bidString(r.Bid, colour), names[r.Contractor], resultString(r.Result))
+	for _, cm := range r.Comments {
+		fmt.Fprintf(&sb, "  %s\n", cm)
+	}
+
+	for i, p := range r.Plays {
+		if i == 0 || r.Plays[i-1].Trick != p.Trick {
+			fmt.Fprintf(&sb, "Trick %d:\n", p.Trick+1)
+		}
+		fmt.Fprintf(&sb, "  %-6s %-4s", names[p.Player], cardStr(p.Card))
+		if p.Lost > 0 {
+			fmt.Fprintf(&sb, "  mistake: %s was best, lost %d %s", cardStr(p.Best), p.Lost, plural(p.Lost, "trick"))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("Mistakes:\n")
+	for i, s := range r.Seats {
+		fmt.Fprintf(&sb, "  %-6s %d %s, %d %s lost\n", names[i],
+			s.Errors, plural(s.Errors, "mistake"), s.TricksLost, plural(s.TricksLost, "trick"))
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+// MarshalJSON exports the review, with cards and bids written as text, e.g.
+// "10♥" and "7NT".
+func (r *Review) MarshalJSON() ([]byte, error) {
+	type play struct {
+		Trick      int    `json:"trick"`
+		Player     int    `json:"player"`
+		Card       string `json:"card"`
+		Tricks     int    `json:"tricks"`
+		Best       string `json:"best"`
+		BestTricks int    `json:"bestTricks"`
+		Lost       int    `json:"lost"`
+	}
+	type seat struct {
+		Errors     int `json:"errors"`
+		TricksLost int `json:"tricksLost"`
+	}
+	out := struct {
+		Contract    string   `json:"contract"`
+		Contractor  int      `json:"contractor"`
+		Result      string   `json:"result"`
+		Par         int      `json:"par"`
+		BestDiscard string   `json:"bestDiscard"`
+		BestPar     int      `json:"bestPar"`
+		Plays       []play   `json:"plays"`
+		Seats       [4]seat  `json:"seats"`
+		Comments    []string `json:"comments"`
+	}{
+		Contract:    bidString(r.Bid, false),
+		Contractor:  r.Contractor,
+		Result:      resultString(r.Result),
+		Par:         r.Par,
+		BestDiscard: plainCards(r.BestDiscard),
+		BestPar:     r.BestPar,
+		Comments:    r.Comments,
+	}
+	for _, p := range r.Plays {
+		out.Plays = append(out.Plays, play{p.Trick, p.Player, plainCard(p.Card), p.Tricks, plainCard(p.Best), p.BestTricks, p.Lost})
+	}
+	for i, s := range r.Seats {
+		out.Seats[i] = seat{s.Errors, s.TricksLost}
+	}
+	return json.Marshal(out)
+}
+
+func plainCard(cd card.Card) string {
+	return cd.Rank.String() + cd.Suit.Symbol(false)
+}
+
+func plainCards(cards *c.List[card.Card]) string {
+	s := make([]string, 0, cards.Size())
+	for _, cd := range *cards {
+		s = append(s, plainCard(cd))
+	}
+	return strings.Join(s, " ")
+}
+
+func bidString(bid game.Bid, colour bool) string {
+	if b, ok := bid.(game.SuitBid); ok {
+		return fmt.Sprintf("%d%s", b.Tricks, b.TrumpSuit.Symbol(colour))
+	}
+	return fmt.Sprint(bid)
+}
+
+func resultString(res game.HandResult) string {
+	switch r := res.(type) {
+	case game.BidWon:
+		return fmt.Sprintf("made with %d %s", r.Tricks, plural(r.Tricks, "trick"))
+	case game.BidLost:
+		return fmt.Sprintf("went down with %d %s", r.Tricks, plural(r.Tricks, "trick"))
+	}
+	return fmt.Sprint(res)
+}
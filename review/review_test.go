@@ -0,0 +1,80 @@
+package review
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/barrettj12/500/controller"
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/player"
+	"github.com/barrettj12/500/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHand(t *testing.T) {
+	// Find a hand which is played out, with easy bots so there are mistakes
+	var rec game.HandRecord
+	for seed := int64(1); ; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		ct := controller.Controller{Rand: r}
+		for i := range ct.Players {
+			ct.Players[i] = player.Adapt(util.E(player.NewBot("easy", r, nil)))
+		}
+		assert.NoError(t, ct.Play(context.Background()))
+		rec = ct.Record()
+		if _, ok := rec.Result.(game.BidWon); ok {
+			break
+		}
+		if _, ok := rec.Result.(game.BidLost); ok {
+			break
+		}
+	}
+
+	r, err := Hand(rec)
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, misere := rec.Bid.(game.MisereBid)
+	plays := 40
+	if misere {
+		plays = 30
+	}
+	assert.Len(t, r.Plays, plays)
+
+	// Each card leads to the position the next player plays from
+	assert.Equal(t, r.Par, r.Plays[0].BestTricks)
+	for i := 1; i < len(r.Plays); i++ {
+		assert.Equal(t, r.Plays[i-1].Tricks, r.Plays[i].BestTricks, "play %d", i)
+	}
+	final := r.Plays[len(r.Plays)-1].Tricks
+	switch res := rec.Result.(type) {
+	case game.BidWon:
+		assert.Equal(t, res.Tricks, final)
+	case game.BidLost:
+		assert.Equal(t, res.Tricks, final)
+	}
+
+	// The tricks lost by each side account for the difference from par
+	lost := [2]int{}
+	for i, s := range r.Seats {
+		lost[i%2] += s.TricksLost
+	}
+	diff := r.Par - final
+	if misere {
+		diff = -diff
+	}
+	contractorSide := rec.Contractor % 2
+	assert.Equal(t, diff, lost[contractorSide]-lost[1-contractorSide])
+
+	var sb strings.Builder
+	assert.NoError(t, r.Write(&sb, [4]string{"North", "East", "South", "West"}, false))
+	assert.Contains(t, sb.String(), "Trick 10:")
+	assert.NotContains(t, sb.String(), "\x1b")
+	_, err = r.MarshalJSON()
+	assert.NoError(t, err)
+
+	_, err = Hand(game.HandRecord{Result: game.Redeal{}})
+	assert.Error(t, err)
+}
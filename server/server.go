@@ -0,0 +1,360 @@
+// Package server hosts many games of 500 at once. Each game is played at its
+// own table, with its own Controller, event log and lifecycle.
+package server
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/barrettj12/500/controller"
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/player"
+
+	c "github.com/barrettj12/collections"
+)
+
+// TableID identifies a table on the server.
+type TableID int
+
+// State is the lifecycle state of a table.
+type State int
+
+const (
+	// Waiting means the table is waiting for players to be seated, or for
+	// the game to be started.
+	Waiting State = iota
+	// Playing means the game is in progress.
+	Playing
+	// Finished means the game is over.
+	Finished
+)
+
+func (s State) String() string {
+	switch s {
+	case Waiting:
+		return "waiting"
+	case Playing:
+		return "playing"
+	case Finished:
+		return "finished"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// TableConfig configures a new table.
+type TableConfig struct {
+	Rules game.HouseRules
+	// Seed is used to shuffle the deck. If zero, a seed is chosen based on
+	// the current time.
+	Seed int64
+	// TimeControl sets a time budget for each player. If nil, there is no
+	// time limit.
+	TimeControl *controller.TimeControl
+	// Pacing decides how long to pause during the game. If nil, the game
+	// runs without pauses.
+	Pacing player.Pacing
+}
+
+// TableStatus describes the current state of a table.
+type TableStatus struct {
+	ID     TableID
+	State  State
+	Seated [4]bool
+	Score  [2]int
+	// Result is the result of the hand, once the game is finished.
+	Result game.HandResult
+	// Err is the error which ended the game, if any.
+	Err error
+}
+
+// LogEntry is an entry in a table's event log. It records either an event
+// sent to a player, or a request sent to a player along with their response.
+type LogEntry struct {
+	Time     time.Time
+	Seat     int
+	Event    player.Event
+	Request  player.Request
+	Response player.Response
+	// Explanation explains a bot's response, if the bot can explain it.
+	Explanation *player.Explanation
+}
+
+// Server hosts many tables at once. It is safe for concurrent use.
+type Server struct {
+	mu     sync.Mutex
+	tables map[TableID]*table
+	nextID TableID
+}
+
+// New returns a new Server with no tables.
+func New() *Server {
+	return &Server{
+		tables: map[TableID]*table{},
+	}
+}
+
+// table holds a single game on the server.
+type table struct {
+	mu      sync.Mutex
+	id      TableID
+	state   State
+	players [4]player.PlayerV2
+	ct      *controller.Controller
+	log     []LogEntry
+	err     error
+	done    chan struct{}
+}
+
+// CreateTable creates a new table, and returns its ID.
+func (s *Server) CreateTable(cfg TableConfig) TableID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	ct := &controller.Controller{
+		Rules:  cfg.Rules,
+		Rand:   rand.New(rand.NewSource(seed)),
+		Pacing: cfg.Pacing,
+	}
+	if cfg.TimeControl != nil {
+		ct.Clock = controller.NewClock(*cfg.TimeControl)
+	}
+
+	id := s.nextID
+	s.nextID++
+	s.tables[id] = &table{
+		id:   id,
+		ct:   ct,
+		done: make(chan struct{}),
+	}
+	return id
+}
+
+// Tables returns the IDs of all tables on the server.
+func (s *Server) Tables() []TableID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]TableID, 0, len(s.tables))
+	for id := range s.tables {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Seat seats a player at the given seat (0-3) of a table. Players can only be
+// seated while the table is waiting.
+func (s *Server) Seat(id TableID, seat int, p player.PlayerV2) error {
+	t, err := s.table(id)
+	if err != nil {
+		return err
+	}
+	if seat < 0 || seat > 3 {
+		return fmt.Errorf("invalid seat %d", seat)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.state != Waiting {
+		return fmt.Errorf("table %d is %s", id, t.state)
+	}
+	if t.players[seat] != nil {
+		return fmt.Errorf("seat %d at table %d is taken", seat, id)
+	}
+	t.players[seat] = p
+	return nil
+}
+
+// Start starts the game at a table, once all seats are filled. The game is
+// played in the background - use Wait to wait for it to finish.
+func (s *Server) Start(ctx context.Context, id TableID) error {
+	t, err := s.table(id)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.state != Waiting {
+		return fmt.Errorf("table %d is %s", id, t.state)
+	}
+	for i, p := range t.players {
+		if p == nil {
+			return fmt.Errorf("seat %d at table %d is empty", i, id)
+		}
+		t.ct.Players[i] = &loggedPlayer{p, i, t}
+	}
+	t.state = Playing
+
+	go func() {
+		err := t.ct.Play(ctx)
+		t.mu.Lock()
+		t.state = Finished
+		t.err = err
+		t.mu.Unlock()
+		close(t.done)
+	}()
+	return nil
+}
+
+// Wait waits for the game at a table to finish, and returns the error which
+// ended it (if any).
+func (s *Server) Wait(ctx context.Context, id TableID) error {
+	t, err := s.table(id)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-t.done:
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return t.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Status returns the current status of a table.
+func (s *Server) Status(id TableID) (TableStatus, error) {
+	t, err := s.table(id)
+	if err != nil {
+		return TableStatus{}, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	status := TableStatus{
+		ID:    id,
+		State: t.state,
+		Err:   t.err,
+	}
+	for i, p := range t.players {
+		status.Seated[i] = p != nil
+	}
+	// The controller is only safe to read once the game is over
+	if t.state == Finished {
+		status.Score = t.ct.Score
+		status.Result = t.ct.Record().Result
+	}
+	return status, nil
+}
+
+// Log returns a copy of a table's event log.
+func (s *Server) Log(id TableID) ([]LogEntry, error) {
+	t, err := s.table(id)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]LogEntry(nil), t.log...), nil
+}
+
+// Remove removes a finished or waiting table from the server.
+func (s *Server) Remove(id TableID) error {
+	t, err := s.table(id)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.state == Playing {
+		return fmt.Errorf("table %d is %s", id, t.state)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tables, id)
+	return nil
+}
+
+func (s *Server) table(id TableID) (*table, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tables[id]
+	if !ok {
+		return nil, fmt.Errorf("table %d not found", id)
+	}
+	return t, nil
+}
+
+func (t *table) record(e LogEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e.Time = time.Now()
+	t.log = append(t.log, e)
+}
+
+// loggedPlayer wraps a player, recording all events and requests sent to it
+// in the table's event log.
+type loggedPlayer struct {
+	player.PlayerV2
+	seat  int
+	table *table
+}
+
+func (p *loggedPlayer) Notify(ctx context.Context, e player.Event) error {
+	p.table.record(LogEntry{Seat: p.seat, Event: copyEvent(e)})
+	return p.PlayerV2.Notify(ctx, e)
+}
+
+func (p *loggedPlayer) Decide(ctx context.Context, r player.Request) (player.Response, error) {
+	logged := copyRequest(r)
+	resp, err := p.PlayerV2.Decide(ctx, r)
+	if err == nil {
+		p.table.record(LogEntry{Seat: p.seat, Request: logged, Response: copyResponse(resp), Explanation: player.ExplainDecision(p.PlayerV2, r)})
+	}
+	return resp, err
+}
+
+// copyEvent copies the lists in an event, as the controller keeps changing
+// them after they are sent.
+func copyEvent(e player.Event) player.Event {
+	switch e := e.(type) {
+	case player.HandEvent:
+		e.Hand = copyList(e.Hand)
+		return e
+	case player.KittyEvent:
+		e.Kitty = copyList(e.Kitty)
+		return e
+	}
+	return e
+}
+
+// copyRequest copies the lists in a request.
+func copyRequest(r player.Request) player.Request {
+	if r, ok := r.(player.PlayRequest); ok {
+		r.Trick = copyList(r.Trick)
+		r.ValidPlays = copyList(r.ValidPlays)
+		return r
+	}
+	return r
+}
+
+// copyResponse copies the lists in a response.
+func copyResponse(resp player.Response) player.Response {
+	if resp, ok := resp.(player.DiscardResponse); ok {
+		resp.Cards = copyList(resp.Cards)
+		return resp
+	}
+	return resp
+}
+
+func copyList[T comparable](l *c.List[T]) *c.List[T] {
+	if l == nil {
+		return nil
+	}
+	return l.Copy()
+}
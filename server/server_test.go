@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/player"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerPlaysTablesConcurrently(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+
+	var ids []TableID
+	for n := 0; n < 5; n++ {
+		id := s.CreateTable(TableConfig{Seed: int64(n + 1)})
+		for i := 0; i < 4; i++ {
+			rp := &player.RandomPlayer{Rand: rand.New(rand.NewSource(int64(n*4 + i)))}
+			assert.NoError(t, s.Seat(id, i, player.Adapt(rp)))
+		}
+		ids = append(ids, id)
+	}
+	assert.Len(t, s.Tables(), 5)
+
+	for _, id := range ids {
+		assert.NoError(t, s.Start(ctx, id))
+	}
+	for _, id := range ids {
+		assert.NoError(t, s.Wait(ctx, id))
+
+		status, err := s.Status(id)
+		assert.NoError(t, err)
+		assert.Equal(t, Finished, status.State)
+		// Random players always pass
+		assert.Equal(t, game.Redeal{}, status.Result)
+
+		log, err := s.Log(id)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, log)
+	}
+}
+
+func TestServerLogCopiesLists(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+	id := s.CreateTable(TableConfig{Seed: 2})
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, s.Seat(id, i, player.Adapt(&player.HeuristicPlayer{})))
+	}
+	assert.NoError(t, s.Start(ctx, id))
+	assert.NoError(t, s.Wait(ctx, id))
+
+	log, err := s.Log(id)
+	assert.NoError(t, err)
+	played := false
+	var hand *player.HandEvent
+	for _, e := range log {
+		switch e := e.Event.(type) {
+		case player.HandEvent:
+			if hand == nil {
+				hand = &e
+			}
+		case player.PlayEvent:
+			played = true
+		}
+	}
+	// The hand was played, but the log shows the hand as it was dealt
+	assert.True(t, played)
+	if assert.NotNil(t, hand) {
+		assert.Equal(t, 10, hand.Hand.Size())
+	}
+}
+
+func TestServerSeatErrors(t *testing.T) {
+	s := New()
+	id := s.CreateTable(TableConfig{})
+	ctx := context.Background()
+
+	assert.Error(t, s.Seat(id, 4, player.Adapt(&player.RandomPlayer{})))
+	assert.NoError(t, s.Seat(id, 0, player.Adapt(&player.RandomPlayer{})))
+	assert.Error(t, s.Seat(id, 0, player.Adapt(&player.RandomPlayer{})))
+	// Can't start until all seats are filled
+	assert.Error(t, s.Start(ctx, id))
+	assert.Error(t, s.Seat(id+1, 0, player.Adapt(&player.RandomPlayer{})))
+
+	status, err := s.Status(id)
+	assert.NoError(t, err)
+	assert.Equal(t, Waiting, status.State)
+	assert.Equal(t, [4]bool{true, false, false, false}, status.Seated)
+}
+
+func TestServerLogsExplanations(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+	id := s.CreateTable(TableConfig{Seed: 1})
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, s.Seat(id, i, player.Adapt(&player.HeuristicPlayer{})))
+	}
+	assert.NoError(t, s.Start(ctx, id))
+	assert.NoError(t, s.Wait(ctx, id))
+
+	log, err := s.Log(id)
+	assert.NoError(t, err)
+	decisions := 0
+	for _, e := range log {
+		if e.Request == nil {
+			assert.Nil(t, e.Explanation)
+			continue
+		}
+		decisions++
+		if assert.NotNil(t, e.Explanation) {
+			assert.Equal(t, e.Seat, e.Explanation.Seat)
+			assert.NotEmpty(t, e.Explanation.Rules)
+		}
+	}
+	assert.NotZero(t, decisions)
+}
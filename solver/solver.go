@@ -0,0 +1,456 @@
+// Package solver solves 500 hands double-dummy, i.e. with every player's
+// cards known. It finds the number of tricks the contractor's side takes
+// with perfect play from both sides.
+package solver
+
+import (
+	"math/bits"
+	"sync"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+
+	c "github.com/barrettj12/collections"
+)
+
+// Position is a point in the card play of a hand.
+type Position struct {
+	Bid        game.Bid
+	Contractor int
+	// Hands are the cards each player has left. In misère, the contractor's
+	// partner doesn't play, so their hand is ignored.
+	Hands [4]*c.List[card.Card]
+	// Leader leads the next trick, if Trick is empty.
+	Leader int
+	// Trick is the cards played so far in the current trick.
+	Trick []game.PlayInfo
+}
+
+// Solve returns the number of tricks (including the current trick) that the
+// contractor's side will take from the position, with perfect play.
+func Solve(pos Position) int {
+	s := newSolver(pos)
+	s.tt = map[ttKey]ttEntry{}
+	return s.solve(0)
+}
+
+// SolveMoves returns, for each card which the player to move can play, the
+// number of tricks (including the current trick) that the contractor's side
+// will take after playing it, with perfect play.
+func SolveMoves(pos Position) map[card.Card]int {
+	s := newSolver(pos)
+	s.tt = map[ttKey]ttEntry{}
+	player := s.turn
+
+	res := map[card.Card]int{}
+	moves, n := s.validMoves(player)
+	for _, m := range moves[:n] {
+		undo := s.play(player, m)
+		res[deck[m]] = s.solve(undo.won)
+		s.undo(undo)
+	}
+	return res
+}
+
+// solve finds the exact value of the current position plus won, using a
+// binary search of null-window searches, which prune much more than a
+// full-window search.
+func (s *solver) solve(won int) int {
+	lo, hi := 0, s.remaining()
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if s.search(mid-1, mid) >= mid {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return won + lo
+}
+
+// deck is the list of cards, indexed by bit position.
+var deck = *game.GetDeck()
+
+// cardIndex maps each card to its bit position.
+var cardIndex = func() map[card.Card]int {
+	m := make(map[card.Card]int, len(deck))
+	for i, cd := range deck {
+		m[cd] = i
+	}
+	return m
+}()
+
+// noSuit is the suit index of the Joker in no trumps and misère.
+const noSuit = 4
+
+var suitIndex = map[card.Suit]int{
+	card.Spades: 0, card.Clubs: 1, card.Diamonds: 2, card.Hearts: 3, card.NoSuit: noSuit,
+}
+
+// rules holds the card rankings for a bid.
+type rules struct {
+	suit     [43]int    // suit of each card under the bid
+	suitMask [5]uint64  // cards of each suit
+	power    [5][43]int // power of each card when a suit is led
+	jokerAny bool       // the Joker can always be played (no trumps)
+}
+
+// rulesCache maps each bid to its rules.
+var rulesCache sync.Map
+
+// rulesFor returns the rules for a bid.
+func rulesFor(bid game.Bid) *rules {
+	if r, ok := rulesCache.Load(bid); ok {
+		return r.(*rules)
+	}
+
+	r := &rules{}
+	for i, cd := range deck {
+		r.suit[i] = suitIndex[bid.Suit(cd)]
+		r.suitMask[r.suit[i]] |= 1 << i
+	}
+	// Power of each card for each lead suit, using the bid's card order
+	for i, cd := range deck {
+		if r.suit[i] != suitIndex[cd.Suit] && cd != card.JokerCard {
+			// Left bower - represents the trump suit, not its own suit
+			continue
+		}
+		order := bid.CardOrder(cd)
+		for j, o := range *order {
+			// The left bower appears twice in some orders: as a trump, and
+			// in its own suit
+			if k, ok := cardIndex[o]; ok && r.power[r.suit[i]][k] == 0 {
+				r.power[r.suit[i]][k] = order.Size() - j
+			}
+		}
+	}
+	switch bid.(type) {
+	case game.NoTrumpsBid, game.MisereBid:
+		r.jokerAny = true
+	}
+
+	rulesCache.Store(bid, r)
+	return r
+}
+
+// solver holds the state of a search.
+type solver struct {
+	// Fixed for the hand
+	*rules
+	jokerMask uint64  // bit for the Joker
+	side      [4]bool // true for the contractor's side
+	next      [4]int  // next player to play
+	trickSize int     // number of plays in a trick
+	tt        map[ttKey]ttEntry
+
+	// Current state
+	hands  [4]uint64
+	leader int
+	turn   int
+	trick  [4]int // cards in the current trick
+	played int    // number of cards in the current trick
+}
+
+type ttKey struct {
+	hands  [4]uint64
+	leader int
+}
+
+// ttEntry holds bounds on the value of a position.
+type ttEntry struct {
+	lower, upper int
+}
+
+func newSolver(pos Position) *solver {
+	s := &solver{
+		rules:     rulesFor(pos.Bid),
+		jokerMask: 1 << cardIndex[card.JokerCard],
+		trickSize: 4,
+	}
+
+	sitOut := -1
+	if _, ok := pos.Bid.(game.MisereBid); ok {
+		sitOut = (pos.Contractor + 2) % 4
+		s.trickSize = 3
+	}
+	for i := range s.next {
+		s.side[i] = i%2 == pos.Contractor%2
+		n := (i + 1) % 4
+		if n == sitOut {
+			n = (n + 1) % 4
+		}
+		s.next[i] = n
+	}
+
+	for i, h := range pos.Hands {
+		if i == sitOut || h == nil {
+			continue
+		}
+		for _, cd := range *h {
+			s.hands[i] |= 1 << cardIndex[cd]
+		}
+	}
+
+	s.leader = pos.Leader
+	s.turn = pos.Leader
+	for _, pl := range pos.Trick {
+		if s.played == 0 {
+			s.leader = pl.Player
+		}
+		s.trick[s.played] = cardIndex[pl.Card]
+		s.played++
+		s.turn = s.next[pl.Player]
+	}
+	return s
+}
+
+// remaining returns the number of tricks left to play, including the
+// current trick.
+func (s *solver) remaining() int {
+	return bits.OnesCount64(s.hands[s.turn])
+}
+
+// search returns the number of tricks the contractor's side takes from the
+// current position. The result is exact if it lies between alpha and beta;
+// otherwise it is a bound on the true value.
+func (s *solver) search(alpha, beta int) int {
+	var key ttKey
+	if s.played == 0 {
+		remaining := s.remaining()
+		// The value is between 0 and remaining
+		if remaining == 0 || beta <= 0 {
+			return 0
+		}
+		if alpha >= remaining {
+			return remaining
+		}
+		key = ttKey{s.hands, s.leader}
+		if e, ok := s.tt[key]; ok {
+			if e.lower >= beta || e.lower == e.upper {
+				return e.lower
+			}
+			if e.upper <= alpha {
+				return e.upper
+			}
+			if e.lower > alpha {
+				alpha = e.lower
+			}
+			if e.upper < beta {
+				beta = e.upper
+			}
+		}
+	}
+	origAlpha, origBeta := alpha, beta
+
+	player := s.turn
+	maximising := s.side[player]
+	best := -1
+	if !maximising {
+		best = 100
+	}
+	moves, n := s.orderedMoves(player)
+	for _, m := range moves[:n] {
+		undo := s.play(player, m)
+		v := undo.won + s.search(alpha-undo.won, beta-undo.won)
+		s.undo(undo)
+
+		if maximising {
+			if v > best {
+				best = v
+			}
+			if best > alpha {
+				alpha = best
+			}
+		} else {
+			if v < best {
+				best = v
+			}
+			if best < beta {
+				beta = best
+			}
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+
+	if s.played == 0 {
+		e, ok := s.tt[key]
+		if !ok {
+			e = ttEntry{0, s.remaining()}
+		}
+		switch {
+		case best <= origAlpha:
+			e.upper = best
+		case best >= origBeta:
+			e.lower = best
+		default:
+			e.lower, e.upper = best, best
+		}
+		s.tt[key] = e
+	}
+	return best
+}
+
+// undoInfo holds what is needed to undo a play.
+type undoInfo struct {
+	player, card int
+	// For a completed trick
+	trick          [4]int
+	leader, played int
+	won            int
+}
+
+// play plays a card, completing the trick if it is the last play.
+func (s *solver) play(player, m int) undoInfo {
+	u := undoInfo{player: player, card: m, trick: s.trick, leader: s.leader, played: s.played}
+	s.hands[player] &^= 1 << m
+	s.trick[s.played] = m
+	s.played++
+	s.turn = s.next[player]
+
+	if s.played == s.trickSize {
+		winner := s.trickWinner()
+		if s.side[winner] {
+			u.won = 1
+		}
+		s.leader = winner
+		s.turn = winner
+		s.played = 0
+	}
+	return u
+}
+
+func (s *solver) undo(u undoInfo) {
+	s.hands[u.player] |= 1 << u.card
+	s.trick = u.trick
+	s.leader = u.leader
+	s.played = u.played
+	s.turn = u.player
+}
+
+// trickWinner returns the winner of the current (complete) trick.
+func (s *solver) trickWinner() int {
+	winner, _ := s.currentWinner()
+	return winner
+}
+
+// maxMoves is the most cards a player can hold during card play.
+const maxMoves = 10
+
+// validMoves returns the cards the player can play, and how many there are.
+func (s *solver) validMoves(player int) (moves [maxMoves]int, n int) {
+	hand := s.hands[player]
+	if s.played > 0 {
+		follow := hand & s.suitMask[s.suit[s.trick[0]]]
+		if follow != 0 {
+			if s.jokerAny {
+				follow |= hand & s.jokerMask
+			}
+			hand = follow
+		}
+	}
+
+	for hand != 0 {
+		m := bits.TrailingZeros64(hand)
+		moves[n] = m
+		n++
+		hand &^= 1 << m
+	}
+	return moves, n
+}
+
+// orderedMoves returns the valid moves, with equivalent cards removed and
+// the most promising moves first.
+func (s *solver) orderedMoves(player int) (distinct [maxMoves]int, n int) {
+	moves, count := s.validMoves(player)
+
+	// Cards are equivalent if they are in the same suit, and no card held
+	// by another player (or in the current trick) lies between them.
+	var others uint64
+	for i, h := range s.hands {
+		if i != player {
+			others |= h
+		}
+	}
+	for i := 0; i < s.played; i++ {
+		others |= 1 << s.trick[i]
+	}
+	for _, m := range moves[:count] {
+		equivalent := false
+		for _, d := range distinct[:n] {
+			if s.suit[d] == s.suit[m] && !s.between(d, m, others) {
+				equivalent = true
+				break
+			}
+		}
+		if !equivalent {
+			distinct[n] = m
+			n++
+		}
+	}
+
+	// Order the moves by a score, highest first
+	var scores [maxMoves]int
+	if s.played == 0 {
+		// Lead high cards first
+		for i, m := range distinct[:n] {
+			scores[i] = s.power[s.suit[m]][m]
+		}
+	} else {
+		lead := s.suit[s.trick[0]]
+		winner, power := s.currentWinner()
+		for i, m := range distinct[:n] {
+			p := s.power[lead][m]
+			switch {
+			case s.side[winner] == s.side[player] || p < power:
+				// Play low when partner is winning, or when we can't win
+				scores[i] = -p - s.power[s.suit[m]][m]
+			default:
+				// Win as cheaply as possible
+				scores[i] = 1000 - p
+			}
+		}
+	}
+	for i := 1; i < n; i++ {
+		for j := i; j > 0 && scores[j] > scores[j-1]; j-- {
+			scores[j], scores[j-1] = scores[j-1], scores[j]
+			distinct[j], distinct[j-1] = distinct[j-1], distinct[j]
+		}
+	}
+	return distinct, n
+}
+
+// currentWinner returns the player winning the current (incomplete) trick,
+// and the power of their card.
+func (s *solver) currentWinner() (winner, power int) {
+	lead := s.suit[s.trick[0]]
+	power = -1
+	player := s.leader
+	for i := 0; i < s.played; i++ {
+		if p := s.power[lead][s.trick[i]]; p > power {
+			power, winner = p, player
+		}
+		player = s.next[player]
+	}
+	return winner, power
+}
+
+// between returns true if any of the given cards lie between cards a and b
+// (which have the same suit).
+func (s *solver) between(a, b int, cards uint64) bool {
+	suit := s.suit[a]
+	lo, hi := s.power[suit][a], s.power[suit][b]
+	if lo > hi {
+		lo, hi = hi, lo
+	}
+	cards &= s.suitMask[suit]
+	for cards != 0 {
+		m := bits.TrailingZeros64(cards)
+		cards &^= 1 << m
+		if p := s.power[suit][m]; p > lo && p < hi {
+			return true
+		}
+	}
+	return false
+}
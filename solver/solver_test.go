@@ -0,0 +1,87 @@
+package solver
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/barrettj12/500/card"
+	"github.com/barrettj12/500/game"
+	"github.com/barrettj12/500/util"
+	"github.com/stretchr/testify/assert"
+
+	c "github.com/barrettj12/collections"
+)
+
+// deal deals n cards to each player from a shuffled deck.
+func deal(r *rand.Rand, n int) [4]*c.List[card.Card] {
+	d := game.GetDeck()
+	util.Shuffle(r, d)
+	var hands [4]*c.List[card.Card]
+	for i := range hands {
+		hands[i] = util.E(d.CopyPart(i*n, i*n+n))
+	}
+	return hands
+}
+
+// bruteForce solves a position by trying every line of play.
+func bruteForce(s *solver) int {
+	if s.played == 0 && s.remaining() == 0 {
+		return 0
+	}
+	player := s.turn
+	best := -1
+	moves, n := s.validMoves(player)
+	for _, m := range moves[:n] {
+		u := s.play(player, m)
+		v := u.won + bruteForce(s)
+		s.undo(u)
+		if best == -1 || (s.side[player] && v > best) || (!s.side[player] && v < best) {
+			best = v
+		}
+	}
+	return best
+}
+
+func TestSolveMatchesBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	bids := []game.Bid{
+		game.SuitBid{7, card.Hearts}, game.SuitBid{6, card.Spades},
+		game.NoTrumpsBid{Tricks: 7}, game.MisereBid{},
+	}
+	for i := 0; i < 200; i++ {
+		pos := Position{
+			Bid:        bids[i%len(bids)],
+			Contractor: r.Intn(4),
+			Hands:      deal(r, 4),
+			Leader:     r.Intn(4),
+		}
+		if _, ok := pos.Bid.(game.MisereBid); ok && pos.Leader == (pos.Contractor+2)%4 {
+			pos.Leader = pos.Contractor
+		}
+		assert.Equal(t, bruteForce(newSolver(pos)), Solve(pos), "position %d: %+v", i, pos)
+	}
+}
+
+func TestSolveMoves(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 50; i++ {
+		hands := deal(r, 4)
+		// Player 0 has led a card, and player 1 is to play
+		lead := util.E(hands[0].Remove(0))
+		pos := Position{
+			Bid:   game.SuitBid{7, card.Spades},
+			Hands: hands,
+			Trick: []game.PlayInfo{{0, lead}},
+		}
+
+		moves := SolveMoves(pos)
+		s := newSolver(pos)
+		valid, n := s.validMoves(1)
+		assert.Len(t, moves, n)
+		for _, m := range valid[:n] {
+			u := s.play(1, m)
+			assert.Equal(t, u.won+bruteForce(s), moves[deck[m]], "position %d: %+v", i, pos)
+			s.undo(u)
+		}
+	}
+}
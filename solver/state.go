@@ -0,0 +1,97 @@
+package solver
+
+import (
+	"github.com/barrettj12/500/card"
+)
+
+// State is a fast model of the card play from a Position, which can be
+// played forward one card at a time. It is used by bots which search by
+// simulating many games.
+type State struct {
+	s   *solver
+	won int
+}
+
+// NewState returns the State for the given position.
+func NewState(pos Position) *State {
+	return &State{s: newSolver(pos)}
+}
+
+// Clone returns a copy of the state, which can be played independently.
+func (st *State) Clone() *State {
+	s := *st.s
+	return &State{&s, st.won}
+}
+
+// Done returns true if all the tricks have been played.
+func (st *State) Done() bool {
+	return st.s.played == 0 && st.s.remaining() == 0
+}
+
+// ToMove returns the player to play next.
+func (st *State) ToMove() int {
+	return st.s.turn
+}
+
+// Moves returns the cards the player to move can play.
+func (st *State) Moves() []card.Card {
+	moves, n := st.s.validMoves(st.s.turn)
+	cards := make([]card.Card, n)
+	for i, m := range moves[:n] {
+		cards[i] = deck[m]
+	}
+	return cards
+}
+
+// Play plays a card for the player to move. It returns true if the card
+// completed a trick.
+func (st *State) Play(cd card.Card) bool {
+	u := st.s.play(st.s.turn, cardIndex[cd])
+	st.won += u.won
+	return st.s.played == 0
+}
+
+// Winning returns the player winning the current trick, or -1 if no cards
+// have been played in it.
+func (st *State) Winning() int {
+	if st.s.played == 0 {
+		return -1
+	}
+	winner, _ := st.s.currentWinner()
+	return winner
+}
+
+// Beats returns true if the card would win the current trick if played now.
+func (st *State) Beats(cd card.Card) bool {
+	if st.s.played == 0 {
+		return true
+	}
+	_, power := st.s.currentWinner()
+	return st.s.power[st.s.suit[st.s.trick[0]]][cardIndex[cd]] > power
+}
+
+// Rank orders cards within their suit under the contract, from lowest to
+// highest. Trumps rank above all other cards.
+func (st *State) Rank(cd card.Card) int {
+	i := cardIndex[cd]
+	suit := st.s.suit[i]
+	rank := st.s.power[suit][i]
+	for lead := 0; lead < 4; lead++ {
+		if lead != suit && st.s.power[lead][i] > 0 {
+			// Wins when another suit is led, so it's a trump (or the Joker)
+			return 100 + rank
+		}
+	}
+	return rank
+}
+
+// SameSide returns true if the players are partners.
+func (st *State) SameSide(a, b int) bool {
+	return st.s.side[a] == st.s.side[b]
+}
+
+// ContractorTricks returns the number of tricks the contractor's side has
+// won since the state was created.
+func (st *State) ContractorTricks() int {
+	return st.won
+}
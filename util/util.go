@@ -1,6 +1,11 @@
 package util
 
-import "fmt"
+import (
+	"fmt"
+	"math/rand"
+
+	c "github.com/barrettj12/collections"
+)
 
 // Utility functions
 
@@ -27,3 +32,34 @@ func Red(s string) string {
 func Grey(s string) string {
 	return fmt.Sprintf("\033[38;2;200;200;200m%s\033[0m", s)
 }
+
+// Randomness
+
+// Intn returns a random int in [0, n) from r, or from the global source if r
+// is nil.
+func Intn(r *rand.Rand, n int) int {
+	if r == nil {
+		return rand.Intn(n)
+	}
+	return r.Intn(n)
+}
+
+// Int63 returns a random non-negative int64 from r, or from the global source
+// if r is nil.
+func Int63(r *rand.Rand) int64 {
+	if r == nil {
+		return rand.Int63()
+	}
+	return r.Int63()
+}
+
+// Shuffle shuffles the list using r, or the global source if r is nil.
+func Shuffle[T comparable](r *rand.Rand, l *c.List[T]) {
+	if r == nil {
+		l.Shuffle()
+		return
+	}
+	r.Shuffle(l.Size(), func(i, j int) {
+		(*l)[i], (*l)[j] = (*l)[j], (*l)[i]
+	})
+}